@@ -0,0 +1,165 @@
+package grole
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+type actorKey struct{}
+
+// Return a copy of the context carrying the actor (the user or service making
+// the changes), WithContext picks it up and the audit log records it.
+// @param context.Context, string
+// @return context.Context
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Return the actor carried by the context.
+// @param context.Context
+// @return string, bool
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
+
+// Return a copy of the enforcer recording its changes in the audit log as
+// made by the given actor.
+// @param string
+// @return *Enforcer
+func (e *Enforcer) WithActor(actor string) *Enforcer {
+	clone := *e
+	clone.actor = actor
+	return &clone
+}
+
+// Return the actor of the enforcer, empty when it isn't set.
+// @return string
+func (e *Enforcer) Actor() string {
+	return e.actor
+}
+
+// AuditQuery selects audit log entries, zero fields don't filter.
+type AuditQuery struct {
+	UserID       uint
+	RoleID       uint
+	PermissionID uint
+	Actor        string
+	Action       Action
+	// Since and Until bound the time of the entries, both included.
+	Since time.Time
+	Until time.Time
+	// Limit the number of entries, all of them when zero.
+	Limit  int
+	Offset int
+}
+
+// Return the audit log entries selected by the query, oldest first.
+// @param AuditQuery
+// @return []models.AuditLog, error
+func (e *Enforcer) GetAuditLog(query AuditQuery) ([]models.AuditLog, error) {
+	db := e.db
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.RoleID != 0 {
+		db = db.Where("role_id = ?", query.RoleID)
+	}
+	if query.PermissionID != 0 {
+		db = db.Where("permission_id = ?", query.PermissionID)
+	}
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", string(query.Action))
+	}
+	if !query.Since.IsZero() {
+		db = db.Where("created_at >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		db = db.Where("created_at <= ?", query.Until)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+
+	entries := []models.AuditLog{}
+	res := db.Order("id").Find(&entries)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return entries, nil
+}
+
+// Return the audit log entries of the user between since and until, zero times don't bound.
+// @param uint, time.Time, time.Time
+// @return []models.AuditLog, error
+func (e *Enforcer) GetUserAuditLog(userID uint, since time.Time, until time.Time) ([]models.AuditLog, error) {
+	return e.GetAuditLog(AuditQuery{UserID: userID, Since: since, Until: until})
+}
+
+// Return the audit log entries of the role between since and until, zero times don't bound.
+// @param uint, time.Time, time.Time
+// @return []models.AuditLog, error
+func (e *Enforcer) GetRoleAuditLog(roleId uint, since time.Time, until time.Time) ([]models.AuditLog, error) {
+	return e.GetAuditLog(AuditQuery{RoleID: roleId, Since: since, Until: until})
+}
+
+// Return the audit log entries of the permission between since and until, zero times don't bound.
+// @param uint, time.Time, time.Time
+// @return []models.AuditLog, error
+func (e *Enforcer) GetPermissionAuditLog(permissionId uint, since time.Time, until time.Time) ([]models.AuditLog, error) {
+	return e.GetAuditLog(AuditQuery{PermissionID: permissionId, Since: since, Until: until})
+}
+
+// writeAudit append an audit log entry for every change to the chain, in the
+// transaction making them.
+func writeAudit(db *gorm.DB, changes []change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	// the time is stored with the precision of every database, so the hash
+	// computed from it can be checked once read back.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	entries := make([]models.AuditLog, 0, len(changes))
+	for _, c := range changes {
+		before, err := auditValue(c.before)
+		if err != nil {
+			return err
+		}
+		after, err := auditValue(c.after)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, models.AuditLog{
+			Actor:        c.actor,
+			Action:       string(c.action),
+			Entity:       string(c.entity),
+			UserID:       c.userID,
+			RoleID:       c.roleID,
+			PermissionID: c.permissionID,
+			Scope:        c.scope,
+			Before:       before,
+			After:        after,
+			CreatedAt:    now,
+		})
+	}
+	return appendAuditChain(db, entries)
+}
+
+// auditValue encode a value of a change as JSON, empty when there is none.
+func auditValue(value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
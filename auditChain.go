@@ -0,0 +1,242 @@
+package grole
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultCheckpointInterval is the number of audit log entries between two
+// signed checkpoints of an export.
+const DefaultCheckpointInterval = 1000
+
+// AuditCheckpoint signs the hash of the audit log chain up to an entry. The
+// final checkpoint of an export follows its last entry, so an export
+// truncated after another checkpoint is detected.
+type AuditCheckpoint struct {
+	ID        uint      `json:"id"`
+	Hash      string    `json:"hash"`
+	Time      time.Time `json:"time"`
+	Final     bool      `json:"final,omitempty"`
+	Signature []byte    `json:"signature"`
+}
+
+// AuditRecord is a line of an audit log export, either an entry or a checkpoint.
+type AuditRecord struct {
+	Entry      *models.AuditLog `json:"entry,omitempty"`
+	Checkpoint *AuditCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// Check that no audit log entry was modified, removed or inserted since it
+// was written. Return nil when the chain is intact, or an Error wrapping
+// ErrAuditEntryModified or ErrAuditChainGap with the id of the first bad entry.
+// @return error
+func (e *Enforcer) VerifyAuditChain() error {
+	return e.walkAuditChain(func(entry models.AuditLog) error {
+		return nil
+	})
+}
+
+// Write the audit log as JSON lines, one record per line, with a checkpoint
+// signed by the key every given number of entries, DefaultCheckpointInterval
+// when zero, and a final one after the last entry. The chain is verified as it
+// is written and a broken chain stops the export with the error of VerifyAuditChain.
+// @param io.Writer, ed25519.PrivateKey, int
+// @return error
+func (e *Enforcer) ExportAuditLog(w io.Writer, key ed25519.PrivateKey, every int) error {
+	if every <= 0 {
+		every = DefaultCheckpointInterval
+	}
+	encoder := json.NewEncoder(w)
+	var last models.AuditLog
+	count := 0
+	err := e.walkAuditChain(func(entry models.AuditLog) error {
+		if err := encoder.Encode(AuditRecord{Entry: &entry}); err != nil {
+			return err
+		}
+		last = entry
+		count++
+		if count%every == 0 {
+			return encoder.Encode(AuditRecord{Checkpoint: newAuditCheckpoint(entry, false, key)})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(AuditRecord{Checkpoint: newAuditCheckpoint(last, true, key)})
+}
+
+// Check an audit log export against the public key of its checkpoints. Return
+// nil when every entry is chained to the previous one and the export ends with
+// a valid final checkpoint, or an Error wrapping ErrAuditEntryModified,
+// ErrAuditChainGap or ErrAuditCheckpointInvalid.
+// @param io.Reader, ed25519.PublicKey
+// @return error
+func VerifyAuditExport(r io.Reader, key ed25519.PublicKey) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var chain auditChain
+	var last models.AuditLog
+	final := false
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return err
+		}
+		switch {
+		case record.Entry != nil:
+			if err := chain.next(*record.Entry); err != nil {
+				return err
+			}
+			last = *record.Entry
+			final = false
+		case record.Checkpoint != nil:
+			checkpoint := record.Checkpoint
+			if checkpoint.ID != last.ID || checkpoint.Hash != chain.hash ||
+				!ed25519.Verify(key, checkpoint.message(), checkpoint.Signature) {
+				return newError(EntityAuditLog, checkpoint.ID, ErrAuditCheckpointInvalid)
+			}
+			final = checkpoint.Final
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !final {
+		return newError(EntityAuditLog, last.ID, ErrAuditCheckpointInvalid)
+	}
+	return nil
+}
+
+// newAuditCheckpoint sign the chain up to the entry.
+func newAuditCheckpoint(entry models.AuditLog, final bool, key ed25519.PrivateKey) *AuditCheckpoint {
+	checkpoint := &AuditCheckpoint{ID: entry.ID, Hash: entry.Hash, Time: time.Now().UTC(), Final: final}
+	checkpoint.Signature = ed25519.Sign(key, checkpoint.message())
+	return checkpoint
+}
+
+// message return the signed content of the checkpoint.
+func (c *AuditCheckpoint) message() []byte {
+	kind := "checkpoint"
+	if c.Final {
+		kind = "final checkpoint"
+	}
+	return []byte(fmt.Sprintf("grole audit %s\n%d\n%s\n%s", kind, c.ID, c.Hash, c.Time.UTC().Format(time.RFC3339Nano)))
+}
+
+// walkAuditChain call fn with every entry of the audit log in order, after
+// checking it is chained to the previous one, and check that the head of the
+// chain is still there. Entries written before the chain existed, without a
+// hash, are skipped.
+func (e *Enforcer) walkAuditChain(fn func(entry models.AuditLog) error) error {
+	// the head is read first, entries appended meanwhile are chained after it.
+	var head models.AuditChain
+	res := e.db.Where("id = ?", 1).Limit(1).Find(&head)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	var chain auditChain
+	var headHash string
+	var entries []models.AuditLog
+	res = e.db.Order("id").FindInBatches(&entries, 500, func(tx *gorm.DB, batch int) error {
+		for _, entry := range entries {
+			if entry.Hash == "" && chain.hash == "" {
+				continue
+			}
+			if err := chain.next(entry); err != nil {
+				return err
+			}
+			if entry.ID == head.LastID {
+				headHash = entry.Hash
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if headHash != head.Hash {
+		return newError(EntityAuditLog, head.LastID, ErrAuditChainGap)
+	}
+	return nil
+}
+
+// auditChain follows the hashes of the audit log entries.
+type auditChain struct {
+	hash string
+}
+
+// next check the entry is the next one of the chain and move to it.
+func (c *auditChain) next(entry models.AuditLog) error {
+	if entry.PrevHash != c.hash {
+		return newError(EntityAuditLog, entry.ID, ErrAuditChainGap)
+	}
+	if auditHash(entry) != entry.Hash {
+		return newError(EntityAuditLog, entry.ID, ErrAuditEntryModified)
+	}
+	c.hash = entry.Hash
+	return nil
+}
+
+// appendAuditChain chain the entries to the last one and insert them, the
+// head of the chain is locked until the transaction ends.
+func appendAuditChain(db *gorm.DB, entries []models.AuditLog) error {
+	var head models.AuditChain
+	res := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", 1).Limit(1).Find(&head)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		head.ID = 1
+		if res := db.Create(&head); res.Error != nil {
+			return res.Error
+		}
+	}
+
+	for i := range entries {
+		entries[i].PrevHash = head.Hash
+		entries[i].Hash = auditHash(entries[i])
+		head.Hash = entries[i].Hash
+	}
+	res = db.Create(&entries)
+	if res.Error != nil {
+		return res.Error
+	}
+	head.LastID = entries[len(entries)-1].ID
+	return db.Model(&head).Updates(map[string]interface{}{"last_id": head.LastID, "hash": head.Hash}).Error
+}
+
+// auditHash return the SHA-256 of the entry content and of the previous hash,
+// as hex. The id isn't hashed since the database assigns it on insert, the
+// order of the entries is kept by the previous hash.
+func auditHash(entry models.AuditLog) string {
+	content, _ := json.Marshal([]interface{}{
+		entry.PrevHash,
+		entry.Actor,
+		entry.Action,
+		entry.Entity,
+		entry.UserID,
+		entry.RoleID,
+		entry.PermissionID,
+		entry.Scope,
+		entry.Before,
+		entry.After,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,354 @@
+package grole
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultCacheSize bounds the number of entries of the memory cache when
+	// Options.CacheSize is zero.
+	DefaultCacheSize = 10000
+	// DefaultCacheTTL is the lifetime of cached entries when Options.Cache is
+	// set and Options.CacheTTL is zero.
+	DefaultCacheTTL = 5 * time.Minute
+)
+
+// Cache stores the effective roles and permissions of users, encoded as JSON.
+// Keys of a user all start with the same prefix so they can be deleted together.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	DeletePrefix(ctx context.Context, prefix string) error
+	Flush(ctx context.Context) error
+}
+
+// Invalidation tells the other instances sharing the database which cached
+// entries a mutation made stale. An Invalidation of a user only affects that
+// user, any other one, including one without an entity, flushes the cache.
+type Invalidation struct {
+	Entity Entity      `json:"entity,omitempty"`
+	Key    interface{} `json:"id,omitempty"`
+	UserID uint        `json:"user_id,omitempty"`
+	Origin string      `json:"origin,omitempty"`
+}
+
+// Broadcaster publishes the invalidations of an enforcer to the other
+// instances and delivers theirs.
+type Broadcaster interface {
+	Publish(ctx context.Context, invalidation Invalidation) error
+	// Subscribe call handle for every published invalidation until the
+	// context is done.
+	Subscribe(ctx context.Context, handle func(Invalidation)) error
+}
+
+// TxBroadcaster is a Broadcaster able to publish in the transaction making the
+// change, so its invalidations are only delivered once the transaction
+// commits, and are delivered even when the instance stops right after. The
+// invalidations of the changes are then published with PublishTx instead of
+// Publish.
+type TxBroadcaster interface {
+	Broadcaster
+	PublishTx(tx *gorm.DB, invalidation Invalidation) error
+}
+
+// memoryCache is an in-process LRU cache whose entries expire after their TTL.
+type memoryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Create an in-process cache holding at most size entries, the least
+// recently used entries are evicted first.
+// @param int
+// @return Cache
+func NewMemoryCache(size int) Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &memoryCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := time.Now().Add(ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.expires = expires
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+func (c *memoryCache) DeletePrefix(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	return nil
+}
+
+// Remove every cached role and permission, on every instance.
+func (e *Enforcer) FlushCache() {
+	e.applyInvalidation(Invalidation{})
+	e.publish(Invalidation{})
+}
+
+// Remove the cached roles and permissions of the user in every scope, on every instance.
+// @param uint
+func (e *Enforcer) ForgetUser(userID uint) {
+	invalidation := Invalidation{Entity: EntityUser, Key: userID, UserID: userID}
+	e.applyInvalidation(invalidation)
+	e.publish(invalidation)
+}
+
+// Receive the invalidations published by the other instances and drop the
+// stale entries of the local cache, until the context is done.
+// @param context.Context
+// @return error
+func (e *Enforcer) ListenInvalidations(ctx context.Context) error {
+	if e.opts.Broadcaster == nil {
+		return nil
+	}
+	return e.opts.Broadcaster.Subscribe(ctx, func(invalidation Invalidation) {
+		if invalidation.Origin != e.instanceID {
+			e.applyInvalidation(invalidation)
+		}
+	})
+}
+
+// invalidate drop the cached entries made stale by the changes and tell the
+// other instances, unless the broadcaster published in the transaction.
+func (e *Enforcer) invalidate(changes []change) {
+	invalidations := changeInvalidations(changes)
+	for _, invalidation := range invalidations {
+		e.applyInvalidation(invalidation)
+		if invalidation.Entity != EntityUser {
+			// the cache is flushed, the other invalidations are applied.
+			break
+		}
+	}
+	if _, ok := e.opts.Broadcaster.(TxBroadcaster); ok {
+		return
+	}
+	for _, invalidation := range invalidations {
+		e.publish(invalidation)
+	}
+}
+
+// publishTx publish the invalidations of the changes in the transaction
+// making them, when the broadcaster is a TxBroadcaster.
+func (e *Enforcer) publishTx(db *gorm.DB, changes []change) error {
+	broadcaster, ok := e.opts.Broadcaster.(TxBroadcaster)
+	if !ok {
+		return nil
+	}
+	for _, invalidation := range changeInvalidations(changes) {
+		invalidation.Origin = e.instanceID
+		if err := broadcaster.PublishTx(db, invalidation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeInvalidations return the invalidations of the changes without
+// duplicates. A change of a role or a permission may affect every user and
+// flushes the cache, the invalidations of users are then left out.
+func changeInvalidations(changes []change) []Invalidation {
+	flush := false
+	for _, c := range changes {
+		if c.entity != EntityUser {
+			flush = true
+		}
+	}
+	var invalidations []Invalidation
+	seen := make(map[Invalidation]bool)
+	for _, c := range changes {
+		var invalidation Invalidation
+		switch {
+		case c.entity == EntityUser && flush:
+			continue
+		case c.entity == EntityUser:
+			invalidation = Invalidation{Entity: EntityUser, Key: c.userID, UserID: c.userID}
+		case c.entity == EntityRole:
+			invalidation = Invalidation{Entity: EntityRole, Key: c.roleID, UserID: c.userID}
+		default:
+			invalidation = Invalidation{Entity: c.entity, Key: c.permissionID, UserID: c.userID}
+		}
+		if !seen[invalidation] {
+			seen[invalidation] = true
+			invalidations = append(invalidations, invalidation)
+		}
+	}
+	return invalidations
+}
+
+// applyInvalidation drop the entries of the local cache made stale by the invalidation.
+func (e *Enforcer) applyInvalidation(invalidation Invalidation) {
+	if e.cache == nil {
+		return
+	}
+	atomic.AddUint64(e.cacheGeneration, 1)
+	var err error
+	if invalidation.Entity == EntityUser {
+		err = e.cache.DeletePrefix(e.ctx, userCacheKey(invalidation.UserID, ""))
+	} else {
+		err = e.cache.Flush(e.ctx)
+	}
+	e.reportError(err)
+}
+
+// publish send the invalidation to the other instances.
+func (e *Enforcer) publish(invalidation Invalidation) {
+	if e.opts.Broadcaster == nil {
+		return
+	}
+	invalidation.Origin = e.instanceID
+	e.reportError(e.opts.Broadcaster.Publish(e.ctx, invalidation))
+}
+
+// userCacheKey return the key of a cached value of the user, or the prefix of
+// all of them when kind is empty.
+func userCacheKey(userID uint, kind string) string {
+	return fmt.Sprintf("user:%d:%s", userID, kind)
+}
+
+// rolesCacheKey return the key of a cached value of the given roles.
+func rolesCacheKey(roles []string, kind string) string {
+	sorted := append([]string{}, roles...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("roles:%q:%s", sorted, kind)
+}
+
+// cached return the value stored under key, or load it and store it. The cache
+// is skipped in transactions, which may see uncommitted changes, a value
+// loaded while the cache was invalidated isn't stored and cache errors fall
+// back to the database.
+func cached[T any](e *Enforcer, key string, load func() (T, error)) (T, error) {
+	return cachedUntil(e, key, nil, load)
+}
+
+// cachedUntil is cached for a value which goes stale at the time returned by
+// expiry, the entry doesn't outlive it. A zero time doesn't bound the entry.
+func cachedUntil[T any](e *Enforcer, key string, expiry func() (time.Time, error), load func() (T, error)) (T, error) {
+	if e.cache == nil || e.txState != nil {
+		return load()
+	}
+	key = key + ":" + e.scope
+
+	var value T
+	data, ok, err := e.cache.Get(e.ctx, key)
+	e.reportError(err)
+	if ok {
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	ttl := e.cacheTTL()
+	if expiry != nil {
+		until, err := expiry()
+		if err != nil {
+			return value, err
+		}
+		if !until.IsZero() && time.Until(until) < ttl {
+			ttl = time.Until(until)
+		}
+	}
+	generation := atomic.LoadUint64(e.cacheGeneration)
+	value, err = load()
+	if err != nil {
+		return value, err
+	}
+	if ttl <= 0 {
+		return value, nil
+	}
+	if data, err := json.Marshal(value); err == nil && atomic.LoadUint64(e.cacheGeneration) == generation {
+		e.reportError(e.cache.Set(e.ctx, key, data, ttl))
+	}
+	return value, nil
+}
+
+// userCacheExpiry return the expiry of the cached values of the user, the
+// next time one of its role assignments or a permission grant starts or expires.
+func (e *Enforcer) userCacheExpiry(userID uint) func() (time.Time, error) {
+	return func() (time.Time, error) {
+		next, err := e.nextRoleChange(userID)
+		if err != nil {
+			return next, wrapError(EntityUser, userID, err)
+		}
+		grant, err := e.nextGrantChange()
+		if err != nil {
+			return next, wrapError(EntityUser, userID, err)
+		}
+		return earliest(next, time.Now(), &grant), nil
+	}
+}
+
+// cacheTTL return the lifetime of cached entries.
+func (e *Enforcer) cacheTTL() time.Duration {
+	if e.opts.CacheTTL > 0 {
+		return e.opts.CacheTTL
+	}
+	return DefaultCacheTTL
+}
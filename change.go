@@ -0,0 +1,225 @@
+package grole
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/mousav1/grole/models"
+)
+
+// Action is the kind of change made by a grole operation.
+type Action string
+
+const (
+	ActionRoleCreated       Action = "role.created"
+	ActionRoleUpdated       Action = "role.updated"
+	ActionRoleDeleted       Action = "role.deleted"
+	ActionPermissionCreated Action = "permission.created"
+	ActionPermissionUpdated Action = "permission.updated"
+	ActionPermissionDeleted Action = "permission.deleted"
+	// a role is assigned to or revoked from a user.
+	ActionRoleAssigned Action = "role.assigned"
+	ActionRoleRevoked  Action = "role.revoked"
+	// an expired role assignment is removed.
+	ActionRoleExpired Action = "role.expired"
+	// a permission is granted to or revoked from a role, or a user when RoleID is zero.
+	ActionPermissionGranted Action = "permission.granted"
+	ActionPermissionRevoked Action = "permission.revoked"
+	// a role starts or stops inheriting from a parent role.
+	ActionParentRoleAdded   Action = "role.parent_added"
+	ActionParentRoleRemoved Action = "role.parent_removed"
+	// a permission on a single resource is granted to or revoked from a user,
+	// or a role when UserID is zero.
+	ActionResourceGranted Action = "resource.granted"
+	ActionResourceRevoked Action = "resource.revoked"
+)
+
+// change describes a mutation made by a grole operation. The entity is the
+// changed one, the ids are zero when the change doesn't concern them.
+type change struct {
+	action       Action
+	entity       Entity
+	userID       uint
+	roleID       uint
+	permissionID uint
+	before       interface{}
+	after        interface{}
+	scope        string
+	actor        string
+	event        Event
+}
+
+// roleCreated record the creation of the role.
+func roleCreated(role models.Role) change {
+	return change{action: ActionRoleCreated, entity: EntityRole, roleID: role.ID,
+		after: roleValue(role), event: &RoleCreated{Role: role}}
+}
+
+// roleUpdated record the update of the role.
+func roleUpdated(before models.Role, after models.Role) change {
+	return change{action: ActionRoleUpdated, entity: EntityRole, roleID: after.ID,
+		before: roleValue(before), after: roleValue(after), event: &RoleUpdated{Before: before, After: after}}
+}
+
+// roleDeleted record the deletion of the role.
+func roleDeleted(role models.Role) change {
+	return change{action: ActionRoleDeleted, entity: EntityRole, roleID: role.ID,
+		before: roleValue(role), event: &RoleDeleted{Role: role}}
+}
+
+// permissionCreated record the creation of the permission.
+func permissionCreated(permission models.Permission) change {
+	return change{action: ActionPermissionCreated, entity: EntityPermission, permissionID: permission.ID,
+		after: permissionValue(permission), event: &PermissionCreated{Permission: permission}}
+}
+
+// permissionUpdated record the update of the permission.
+func permissionUpdated(before models.Permission, after models.Permission) change {
+	return change{action: ActionPermissionUpdated, entity: EntityPermission, permissionID: after.ID,
+		before: permissionValue(before), after: permissionValue(after), event: &PermissionUpdated{Before: before, After: after}}
+}
+
+// permissionDeleted record the deletion of the permission.
+func permissionDeleted(permission models.Permission) change {
+	return change{action: ActionPermissionDeleted, entity: EntityPermission, permissionID: permission.ID,
+		before: permissionValue(permission), event: &PermissionDeleted{Permission: permission}}
+}
+
+// userRoleChange record the role assigned to or revoked from the user.
+func userRoleChange(action Action, userID uint, role models.Role) change {
+	c := change{action: action, entity: EntityUser, userID: userID, roleID: role.ID}
+	if action == ActionRoleAssigned {
+		c.event = &RoleAssigned{UserID: userID, Role: role}
+	} else {
+		c.event = &RoleRevoked{UserID: userID, Role: role}
+	}
+	return c.value(map[string]interface{}{"role": role.Name})
+}
+
+// roleAssigned record the role assigned to the user for the validity window,
+// nil bounds don't limit it.
+func roleAssigned(userID uint, role models.Role, validFrom *time.Time, expiresAt *time.Time) change {
+	c := change{action: ActionRoleAssigned, entity: EntityUser, userID: userID, roleID: role.ID,
+		event: &RoleAssigned{UserID: userID, Role: role, ValidFrom: validFrom, ExpiresAt: expiresAt}}
+	value := map[string]interface{}{"role": role.Name}
+	if validFrom != nil {
+		value["valid_from"] = *validFrom
+	}
+	if expiresAt != nil {
+		value["expires_at"] = *expiresAt
+	}
+	return c.value(value)
+}
+
+// permissionGranted record the permission granted to the role for the
+// validity window, nil bounds don't limit it.
+func permissionGranted(role models.Role, permission models.Permission, validFrom *time.Time, expiresAt *time.Time) change {
+	c := change{action: ActionPermissionGranted, entity: EntityRole, roleID: role.ID, permissionID: permission.ID,
+		event: &PermissionGranted{Role: role, Permission: permission, ValidFrom: validFrom, ExpiresAt: expiresAt}}
+	value := map[string]interface{}{"role": role.Name, "permission": permission.Name}
+	if validFrom != nil {
+		value["valid_from"] = *validFrom
+	}
+	if expiresAt != nil {
+		value["expires_at"] = *expiresAt
+	}
+	return c.value(value)
+}
+
+// roleExpired record the removal of the expired assignment of the role.
+func roleExpired(userID uint, role models.Role, expiresAt time.Time) change {
+	c := change{action: ActionRoleExpired, entity: EntityUser, userID: userID, roleID: role.ID,
+		event: &RoleExpired{UserID: userID, Role: role, ExpiresAt: expiresAt}}
+	return c.value(map[string]interface{}{"role": role.Name, "expires_at": expiresAt})
+}
+
+// userPermissionChange record the permission granted to or revoked from the user.
+func userPermissionChange(action Action, userID uint, permission models.Permission) change {
+	c := change{action: action, entity: EntityUser, userID: userID, permissionID: permission.ID}
+	if action == ActionPermissionGranted {
+		c.event = &PermissionGranted{UserID: userID, Permission: permission}
+	} else {
+		c.event = &PermissionRevoked{UserID: userID, Permission: permission}
+	}
+	return c.value(map[string]interface{}{"permission": permission.Name})
+}
+
+// rolePermissionChange record the permission granted to or revoked from the role.
+func rolePermissionChange(action Action, role models.Role, permission models.Permission) change {
+	c := change{action: action, entity: EntityRole, roleID: role.ID, permissionID: permission.ID}
+	if action == ActionPermissionGranted {
+		c.event = &PermissionGranted{Role: role, Permission: permission}
+	} else {
+		c.event = &PermissionRevoked{Role: role, Permission: permission}
+	}
+	return c.value(map[string]interface{}{"role": role.Name, "permission": permission.Name})
+}
+
+// parentRoleChange record the parent role added to or removed from the role.
+func parentRoleChange(action Action, role models.Role, parent models.Role) change {
+	c := change{action: action, entity: EntityRole, roleID: role.ID}
+	if action == ActionParentRoleAdded {
+		c.event = &ParentRoleAdded{Role: role, Parent: parent}
+	} else {
+		c.event = &ParentRoleRemoved{Role: role, Parent: parent}
+	}
+	return c.value(map[string]interface{}{"parent_id": parent.ID, "parent": parent.Name})
+}
+
+// resourceChange record the permission on the resource granted to or revoked
+// from the user, or the role when userID is zero.
+func resourceChange(action Action, userID uint, role models.Role, permission models.Permission, resourceType string, resourceID uint) change {
+	c := change{action: action, entity: EntityUser, userID: userID, roleID: role.ID, permissionID: permission.ID}
+	if userID == 0 {
+		c.entity = EntityRole
+	}
+	if action == ActionResourceGranted {
+		c.event = &ResourceGranted{UserID: userID, Role: role, Permission: permission, ResourceType: resourceType, ResourceID: resourceID}
+	} else {
+		c.event = &ResourceRevoked{UserID: userID, Role: role, Permission: permission, ResourceType: resourceType, ResourceID: resourceID}
+	}
+	value := map[string]interface{}{"permission": permission.Name, "resource_type": resourceType, "resource_id": resourceID}
+	if userID == 0 {
+		value["role"] = role.Name
+	}
+	return c.value(value)
+}
+
+// value set the value the change adds, or removes for revocations.
+func (c change) value(value interface{}) change {
+	switch c.action {
+	case ActionRoleRevoked, ActionRoleExpired, ActionPermissionRevoked, ActionParentRoleRemoved, ActionResourceRevoked:
+		c.before = value
+	default:
+		c.after = value
+	}
+	return c
+}
+
+// roleValue return the recorded value of a role.
+func roleValue(role models.Role) map[string]interface{} {
+	return map[string]interface{}{"id": role.ID, "name": role.Name, "description": role.Description, "scope": role.Scope}
+}
+
+// permissionValue return the recorded value of a permission.
+func permissionValue(permission models.Permission) map[string]interface{} {
+	return map[string]interface{}{"id": permission.ID, "name": permission.Name, "description": permission.Description}
+}
+
+// committed apply the changes of a committed transaction.
+func (e *Enforcer) committed(changes []change) {
+	if len(changes) == 0 {
+		return
+	}
+	e.invalidate(changes)
+	e.afterCommit(changes)
+}
+
+// newInstanceID return a random id telling the changes of an enforcer apart
+// from the ones of the other instances.
+func newInstanceID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
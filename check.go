@@ -0,0 +1,62 @@
+package grole
+
+// CheckResult tells which of the requested roles or permissions the user has
+// and which ones are missing.
+type CheckResult struct {
+	Satisfied []string
+	Missing   []string
+}
+
+// Determine if the user has every requested role or permission.
+// @return bool
+func (r CheckResult) Allowed() bool {
+	return len(r.Missing) == 0
+}
+
+// Check which of the given roles name the user has, inherited roles included.
+// @param uint, string
+// @return CheckResult, error
+func (e *Enforcer) CheckRoles(userID uint, rolesName ...string) (CheckResult, error) {
+	roles, err := e.GetAllRoles(userID)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	granted := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		granted[role.Name] = true
+	}
+	return newCheckResult(rolesName, func(name string) bool {
+		return granted[name]
+	}), nil
+}
+
+// Check which of the given permissions name the user has, granted wildcards included.
+// @param uint, string
+// @return CheckResult, error
+func (e *Enforcer) CheckPermissions(userID uint, permissionsName ...string) (CheckResult, error) {
+	permissions, err := e.GetAllPermissions(userID)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	return newCheckResult(permissionsName, func(name string) bool {
+		return e.permissionGranted(permissions, name)
+	}), nil
+}
+
+// newCheckResult split the requested names, without duplicates, by the result of has.
+func newCheckResult(names []string, has func(name string) bool) CheckResult {
+	result := CheckResult{Satisfied: []string{}, Missing: []string{}}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if has(name) {
+			result.Satisfied = append(result.Satisfied, name)
+		} else {
+			result.Missing = append(result.Missing, name)
+		}
+	}
+	return result
+}
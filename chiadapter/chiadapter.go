@@ -0,0 +1,123 @@
+// Package chiadapter enforces grole roles and permissions on Chi routes, with
+// the decision of the middleware package.
+package chiadapter
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mousav1/grole/middleware"
+)
+
+type Options struct {
+	// Middleware checks the requirements and responds to the requests that
+	// don't go through, middleware.Default() when nil.
+	Middleware *middleware.Middleware
+}
+
+// Adapter builds the Chi middlewares enforcing requirements with its options.
+type Adapter struct {
+	opts Options
+}
+
+var defaultAdapter = &Adapter{}
+
+// set the options of the adapter and make it the default one
+// @param Options
+// @return *Adapter
+func New(opt Options) *Adapter {
+	defaultAdapter = &Adapter{opts: opt}
+	return defaultAdapter
+}
+
+// Only let through users meeting the requirement.
+// @param middleware.Requirement
+// @return func(http.Handler) http.Handler
+func (a *Adapter) Require(requirement middleware.Requirement) func(http.Handler) http.Handler {
+	return a.middleware().Require(requirement)
+}
+
+// Only let through users having at least one of the given permissions.
+// @param string
+// @return func(http.Handler) http.Handler
+func (a *Adapter) RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return a.Require(middleware.AnyPermission(permissions...))
+}
+
+// Only let through users having every given permission.
+// @param string
+// @return func(http.Handler) http.Handler
+func (a *Adapter) RequireAllPermissions(permissions ...string) func(http.Handler) http.Handler {
+	return a.Require(middleware.AllPermissions(permissions...))
+}
+
+// Only let through users having at least one of the given roles.
+// @param string
+// @return func(http.Handler) http.Handler
+func (a *Adapter) RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
+	return a.Require(middleware.AnyRole(roles...))
+}
+
+// Only let through users having every given role.
+// @param string
+// @return func(http.Handler) http.Handler
+func (a *Adapter) RequireAllRoles(roles ...string) func(http.Handler) http.Handler {
+	return a.Require(middleware.AllRoles(roles...))
+}
+
+// Return a router whose routes only let through users meeting the requirement.
+// @param chi.Router, middleware.Requirement
+// @return chi.Router
+func (a *Adapter) With(r chi.Router, requirement middleware.Requirement) chi.Router {
+	return r.With(a.Require(requirement))
+}
+
+// middleware return the middleware of the adapter.
+func (a *Adapter) middleware() *middleware.Middleware {
+	if a.opts.Middleware != nil {
+		return a.opts.Middleware
+	}
+	return middleware.Default()
+}
+
+// Only let through users meeting the requirement.
+// @param middleware.Requirement
+// @return func(http.Handler) http.Handler
+func Require(requirement middleware.Requirement) func(http.Handler) http.Handler {
+	return defaultAdapter.Require(requirement)
+}
+
+// Only let through users having at least one of the given permissions.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return defaultAdapter.RequirePermission(permissions...)
+}
+
+// Only let through users having every given permission.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequireAllPermissions(permissions ...string) func(http.Handler) http.Handler {
+	return defaultAdapter.RequireAllPermissions(permissions...)
+}
+
+// Only let through users having at least one of the given roles.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
+	return defaultAdapter.RequireAnyRole(roles...)
+}
+
+// Only let through users having every given role.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequireAllRoles(roles ...string) func(http.Handler) http.Handler {
+	return defaultAdapter.RequireAllRoles(roles...)
+}
+
+// Return a router whose routes only let through users meeting the requirement.
+// @param chi.Router, middleware.Requirement
+// @return chi.Router
+func With(r chi.Router, requirement middleware.Requirement) chi.Router {
+	return defaultAdapter.With(r, requirement)
+}
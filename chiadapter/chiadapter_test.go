@@ -0,0 +1,81 @@
+package chiadapter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-chi/chi/v5"
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/chiadapter"
+	"github.com/mousav1/grole/middleware"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// enforcer return an enforcer on an in-memory database, without grole.New.
+func enforcer(t *testing.T) *grole.Enforcer {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return grole.NewEnforcer(grole.Options{DB: db})
+}
+
+// userIs is a requirement only met by the given user.
+func userIs(userID uint) middleware.Requirement {
+	return func(e *grole.Enforcer, user uint) (bool, error) {
+		return user == userID, nil
+	}
+}
+
+func TestWith(t *testing.T) {
+	adapter := chiadapter.New(chiadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{
+			Enforcer: enforcer(t),
+			UserID:   middleware.FromHeader("X-User-ID"),
+		}),
+	})
+
+	router := chi.NewRouter()
+	adapter.With(router, userIs(1)).Get("/invoices", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for userID, status := range map[string]int{
+		"1": http.StatusNoContent,
+		"2": http.StatusForbidden,
+		"":  http.StatusUnauthorized,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+		r.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		require.Equal(t, status, w.Code, userID)
+	}
+}
+
+func TestNoEnforcer(t *testing.T) {
+	var failure error
+	adapter := chiadapter.New(chiadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{
+			UserID: middleware.FromHeader("X-User-ID"),
+			Error: func(w http.ResponseWriter, r *http.Request, err error) {
+				failure = err
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+		}),
+	})
+
+	router := chi.NewRouter()
+	adapter.With(router, userIs(1)).Get("/invoices", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+	r.Header.Set("X-User-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.ErrorIs(t, failure, middleware.ErrNoEnforcer)
+}
@@ -0,0 +1,30 @@
+module github.com/mousav1/grole/chiadapter
+
+go 1.19
+
+replace github.com/mousav1/grole => ../
+
+require (
+	github.com/glebarez/sqlite v1.6.0
+	github.com/go-chi/chi/v5 v5.0.8
+	github.com/mousav1/grole v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.8.1
+	gorm.io/gorm v1.24.3
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/glebarez/go-sqlite v1.20.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/mattn/go-isatty v0.0.16 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20200410134404-eec4a21b6bb0 // indirect
+	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	modernc.org/libc v1.21.5 // indirect
+	modernc.org/mathutil v1.5.0 // indirect
+	modernc.org/memory v1.4.0 // indirect
+	modernc.org/sqlite v1.20.0 // indirect
+)
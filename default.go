@@ -0,0 +1,591 @@
+package grole
+
+import (
+	"context"
+	"crypto/ed25519"
+	"io"
+	"time"
+
+	"github.com/mousav1/grole/models"
+)
+
+// defaultEnforcer is used by the package level functions, it is set by New.
+var defaultEnforcer *Enforcer
+
+// Return the default enforcer created by New.
+// @return *Enforcer
+func Default() *Enforcer {
+	return defaultEnforcer
+}
+
+// Return the default enforcer bound to the given context.
+// @param context.Context
+// @return *Enforcer
+func WithContext(ctx context.Context) *Enforcer {
+	return defaultEnforcer.WithContext(ctx)
+}
+
+// Return the default enforcer working in the given scope.
+// @param string
+// @return *Enforcer
+func WithScope(scope string) *Enforcer {
+	return defaultEnforcer.WithScope(scope)
+}
+
+// Return the default enforcer recording its changes as made by the given actor.
+// @param string
+// @return *Enforcer
+func WithActor(actor string) *Enforcer {
+	return defaultEnforcer.WithActor(actor)
+}
+
+// Run the given function in a transaction of the default enforcer.
+// @param func(tx *Tx) error
+// @return error
+func Transaction(fn func(tx *Tx) error) error {
+	return defaultEnforcer.Transaction(fn)
+}
+
+// delete the given role
+// @param uint
+// @return bool, error
+func DeleteRole(roleId uint) (bool, error) {
+	return defaultEnforcer.DeleteRole(roleId)
+}
+
+// update the given role
+// @param uint
+// @return bool, error
+func UpdateRole(roleId uint, newRole models.Role) (bool, error) {
+	return defaultEnforcer.UpdateRole(roleId, newRole)
+}
+
+// delete the given permission
+// @param uint
+// @return bool, error
+func DeletePermission(permissionId uint) (bool, error) {
+	return defaultEnforcer.DeletePermission(permissionId)
+}
+
+// update the given permission
+// @param uint
+// @return bool, error
+func UpdatePermission(permissionId uint, newPermission models.Permission) (bool, error) {
+	return defaultEnforcer.UpdatePermission(permissionId, newPermission)
+}
+
+// Show All Permission With Role
+// @return []models.Permission, error
+func FindAllPermission() ([]models.Permission, error) {
+	return defaultEnforcer.FindAllPermission()
+}
+
+// Find roles of each permission
+// @param string
+// @return []models.Role
+func Roles(permissions ...string) ([]models.Role, error) {
+	return defaultEnforcer.Roles(permissions...)
+}
+
+// find Permission By Name and Show each with Role
+// @param string
+// @return models.Permission, error
+func FindPermissionByName(name string) (models.Permission, error) {
+	return defaultEnforcer.FindPermissionByName(name)
+}
+
+// find Permission By Id and Show each with Role
+// @param uint
+// @return models.Permission, error
+func FindPermissionById(id uint) (models.Permission, error) {
+	return defaultEnforcer.FindPermissionById(id)
+}
+
+// find Permission or Create Permission If not found, a malformed name returns ErrInvalidPermissionName.
+// @param models.Permission
+// @return models.Permission, error
+func FindOrCreatePermission(permission models.Permission) (models.Permission, error) {
+	return defaultEnforcer.FindOrCreatePermission(permission)
+}
+
+// Revoke the given role by id for permission
+// @param uint, uint
+// @return bool, error
+func RemoveRoleByIdFromPermission(permissionId uint, roleId uint) (bool, error) {
+	return defaultEnforcer.RemoveRoleByIdFromPermission(permissionId, roleId)
+}
+
+// Revoke the given role by name for permission
+// @param uint, string
+// @return bool, error
+func RemoveRoleByNameFromPermission(permissionId uint, roleName string) (bool, error) {
+	return defaultEnforcer.RemoveRoleByNameFromPermission(permissionId, roleName)
+}
+
+// Revoke the given role for permission
+// @param uint, string
+// @return bool, error
+func RemoveRoleFromPermission(permissionId uint, roleName string) (bool, error) {
+	return defaultEnforcer.RemoveRoleFromPermission(permissionId, roleName)
+}
+
+// Return the number of permissions Role.
+// @param uint
+// @return int64, error
+func CountRoleFromPermission(permissionId uint) (int64, error) {
+	return defaultEnforcer.CountRoleFromPermission(permissionId)
+}
+
+// Remove all current Role for Permission.
+// @param uint
+// @return bool, error
+func RemoveAllRoleFromPermission(permissionId uint) (bool, error) {
+	return defaultEnforcer.RemoveAllRoleFromPermission(permissionId)
+}
+
+// Remove all current Permission role and set the given ones.
+// @param uint, string
+// @return []models.Role, error
+func SyncRolesFromPermission(permissionId uint, roles ...string) ([]models.Role, error) {
+	return defaultEnforcer.SyncRolesFromPermission(permissionId, roles...)
+}
+
+// Find All Role
+// @return []models.Role, error
+func FindAllRole() ([]models.Role, error) {
+	return defaultEnforcer.FindAllRole()
+}
+
+// Return all Permissions the Role.
+// @param string
+// @return []models.Permission
+func Permissions(roles ...string) ([]models.Permission, error) {
+	return defaultEnforcer.Permissions(roles...)
+}
+
+// Find Role By Name
+// @param string
+// @return models.Role, error
+func FindRoleByName(name string) (models.Role, error) {
+	return defaultEnforcer.FindRoleByName(name)
+}
+
+// Find Role By Id
+// @param uint
+// @return models.Role, error
+func FindRoleById(roleId uint) (models.Role, error) {
+	return defaultEnforcer.FindRoleById(roleId)
+}
+
+// Find Or Create Role
+// @param models.Role
+// @return models.Role, error
+func FindOrCreateRole(role models.Role) (models.Role, error) {
+	return defaultEnforcer.FindOrCreateRole(role)
+}
+
+// Revoke the given Permission by id for Role
+// @param uint, uint
+// @return bool, error
+func RemovePermissionByIdFromRole(roleId uint, permissionId uint) (bool, error) {
+	return defaultEnforcer.RemovePermissionByIdFromRole(roleId, permissionId)
+}
+
+// Revoke the given Permission by name for Role
+// @param string, string
+// @return bool, error
+func RemovePermissionByNameFromRole(roleName string, permissionName string) (bool, error) {
+	return defaultEnforcer.RemovePermissionByNameFromRole(roleName, permissionName)
+}
+
+// Return the number of Role permissions.
+// @param uint
+// @return int64, error
+func CountPermissionFromRole(roleId uint) (int64, error) {
+	return defaultEnforcer.CountPermissionFromRole(roleId)
+}
+
+// Remove all current Permission for Role.
+// @param uint
+// @return bool, error
+func RemoveAllPermissionFromRole(roleId uint) (bool, error) {
+	return defaultEnforcer.RemoveAllPermissionFromRole(roleId)
+}
+
+// Remove all current role Permission and set the given ones.
+// @param uint, string
+// @return []models.Permission, error
+func SyncPermissionsFromRole(roleId uint, permissions ...string) ([]models.Permission, error) {
+	return defaultEnforcer.SyncPermissionsFromRole(roleId, permissions...)
+}
+
+// Assign the given Permissions to the Role.
+// @param uint, string
+// @return []models.Permission, error
+func AssignPermissionsFromRole(roleId uint, permissions ...string) ([]models.Permission, error) {
+	return defaultEnforcer.AssignPermissionsFromRole(roleId, permissions...)
+}
+
+// Determine if the Role may perform the given permission.
+// @param uint, string
+// @return models.Permission, error
+func HasPermissionTo(roleId uint, permissionName string) (models.Permission, error) {
+	return defaultEnforcer.HasPermissionTo(roleId, permissionName)
+}
+
+// Return all the Roles the user.
+// @param uint
+// @return []models.Role, error
+func GetRole(userID uint) ([]models.Role, error) {
+	return defaultEnforcer.GetRole(userID)
+}
+
+// Return all the Roles Name the user.
+// @param uint
+// @return []string, error
+func GetRoleNames(userID uint) ([]string, error) {
+	return defaultEnforcer.GetRoleNames(userID)
+}
+
+// Return all the permissions the user, the direct ones and the ones of its roles
+// and the roles they inherit from.
+// @param uint
+// @return []models.Permission, error
+func GetAllPermissions(userID uint) ([]models.Permission, error) {
+	return defaultEnforcer.GetAllPermissions(userID)
+}
+
+// Assign the given roles to the User.
+// @param uint, string
+// @return bool, error
+func AssignRoles(userID uint, Roles ...string) (bool, error) {
+	return defaultEnforcer.AssignRoles(userID, Roles...)
+}
+
+// Revoke the given role by id for user
+// @param uint, uint
+// @return bool, error
+func RemoveRoleByIdFromUser(userID uint, roleId uint) (bool, error) {
+	return defaultEnforcer.RemoveRoleByIdFromUser(userID, roleId)
+}
+
+// Revoke the given role by name for user
+// @param uint, string
+// @return bool, error
+func RemoveRoleByNameFromUser(userID uint, roleName string) (bool, error) {
+	return defaultEnforcer.RemoveRoleByNameFromUser(userID, roleName)
+}
+
+// Remove all current roles for user.
+// @param uint
+// @return bool, error
+func RemoveAllRoleFromUser(userID uint) (bool, error) {
+	return defaultEnforcer.RemoveAllRoleFromUser(userID)
+}
+
+// Remove all current user roles and set the given ones.
+// @param uint, string
+// @return bool, error
+func SyncRolesFromUser(userID uint, Roles ...string) (bool, error) {
+	return defaultEnforcer.SyncRolesFromUser(userID, Roles...)
+}
+
+// Determine if the user has  of the given role id.
+// @param uint, uint
+// @return bool, error
+func HasRole(userID uint, roleId uint) (bool, error) {
+	return defaultEnforcer.HasRole(userID, roleId)
+}
+
+// Determine if the user has of the given roles name, inherited roles included.
+// @param uint, uint
+// @return bool, error
+func HasAnyRole(userID uint, rolesName ...string) (bool, error) {
+	return defaultEnforcer.HasAnyRole(userID, rolesName...)
+}
+
+// Determine if the user has all of the given roles name, inherited roles included.
+// @param uint, uint
+// @return bool, error
+func HasAllRole(userID uint, rolesName ...string) (bool, error) {
+	return defaultEnforcer.HasAllRole(userID, rolesName...)
+}
+
+// Determine if the user has all of the given permissions name, granted wildcards included.
+// @param uint, uint
+// @return bool, error
+func HasAllPermission(userID uint, permissionsName ...string) (bool, error) {
+	return defaultEnforcer.HasAllPermission(userID, permissionsName...)
+}
+
+// Determine if the User has of the given permissions name, granted wildcards included.
+// @param uint, string
+// @return bool, error
+func HasAnyPermissions(userID uint, permissionsName ...string) (bool, error) {
+	return defaultEnforcer.HasAnyPermissions(userID, permissionsName...)
+}
+
+// Give the given permissions directly to the user, without a role.
+// @param uint, string
+// @return bool, error
+func GivePermissionToUser(userID uint, permissions ...string) (bool, error) {
+	return defaultEnforcer.GivePermissionToUser(userID, permissions...)
+}
+
+// Revoke the given direct permissions from the user.
+// @param uint, string
+// @return bool, error
+func RevokePermissionFromUser(userID uint, permissions ...string) (bool, error) {
+	return defaultEnforcer.RevokePermissionFromUser(userID, permissions...)
+}
+
+// Remove all current direct permissions of the user and set the given ones.
+// @param uint, string
+// @return bool, error
+func SyncUserPermissions(userID uint, permissions ...string) (bool, error) {
+	return defaultEnforcer.SyncUserPermissions(userID, permissions...)
+}
+
+// Return the permissions given directly to the user, without the ones of its roles.
+// @param uint
+// @return []models.Permission, error
+func GetDirectPermissions(userID uint) ([]models.Permission, error) {
+	return defaultEnforcer.GetDirectPermissions(userID)
+}
+
+// Make the role inherit the roles and permissions of the given parent roles.
+// @param uint, string
+// @return bool, error
+func AddParentRole(roleId uint, parents ...string) (bool, error) {
+	return defaultEnforcer.AddParentRole(roleId, parents...)
+}
+
+// Stop the role from inheriting the given parent roles.
+// @param uint, string
+// @return bool, error
+func RemoveParentRole(roleId uint, parents ...string) (bool, error) {
+	return defaultEnforcer.RemoveParentRole(roleId, parents...)
+}
+
+// Return the direct parent roles of the role.
+// @param uint
+// @return []models.Role, error
+func GetParentRoles(roleId uint) ([]models.Role, error) {
+	return defaultEnforcer.GetParentRoles(roleId)
+}
+
+// Return the roles the role inherits from, directly or through other roles.
+// @param uint
+// @return []models.Role, error
+func GetInheritedRoles(roleId uint) ([]models.Role, error) {
+	return defaultEnforcer.GetInheritedRoles(roleId)
+}
+
+// Return all the Roles the user, the assigned ones and the ones they inherit from.
+// @param uint
+// @return []models.Role, error
+func GetAllRoles(userID uint) ([]models.Role, error) {
+	return defaultEnforcer.GetAllRoles(userID)
+}
+
+// Check which of the given roles name the user has, inherited roles included.
+// @param uint, string
+// @return CheckResult, error
+func CheckRoles(userID uint, rolesName ...string) (CheckResult, error) {
+	return defaultEnforcer.CheckRoles(userID, rolesName...)
+}
+
+// Check which of the given permissions name the user has, granted wildcards included.
+// @param uint, string
+// @return CheckResult, error
+func CheckPermissions(userID uint, permissionsName ...string) (CheckResult, error) {
+	return defaultEnforcer.CheckPermissions(userID, permissionsName...)
+}
+
+// Remove every cached role and permission of the default enforcer, on every instance.
+func FlushCache() {
+	defaultEnforcer.FlushCache()
+}
+
+// Remove the cached roles and permissions of the user from the default enforcer, on every instance.
+// @param uint
+func ForgetUser(userID uint) {
+	defaultEnforcer.ForgetUser(userID)
+}
+
+// Receive the invalidations published by the other instances and drop the
+// stale entries of the default enforcer cache, until the context is done.
+// @param context.Context
+// @return error
+func ListenInvalidations(ctx context.Context) error {
+	return defaultEnforcer.ListenInvalidations(ctx)
+}
+
+// Return the audit log entries selected by the query, oldest first.
+// @param AuditQuery
+// @return []models.AuditLog, error
+func GetAuditLog(query AuditQuery) ([]models.AuditLog, error) {
+	return defaultEnforcer.GetAuditLog(query)
+}
+
+// Return the audit log entries of the user between since and until, zero times don't bound.
+// @param uint, time.Time, time.Time
+// @return []models.AuditLog, error
+func GetUserAuditLog(userID uint, since time.Time, until time.Time) ([]models.AuditLog, error) {
+	return defaultEnforcer.GetUserAuditLog(userID, since, until)
+}
+
+// Return the audit log entries of the role between since and until, zero times don't bound.
+// @param uint, time.Time, time.Time
+// @return []models.AuditLog, error
+func GetRoleAuditLog(roleId uint, since time.Time, until time.Time) ([]models.AuditLog, error) {
+	return defaultEnforcer.GetRoleAuditLog(roleId, since, until)
+}
+
+// Return the audit log entries of the permission between since and until, zero times don't bound.
+// @param uint, time.Time, time.Time
+// @return []models.AuditLog, error
+func GetPermissionAuditLog(permissionId uint, since time.Time, until time.Time) ([]models.AuditLog, error) {
+	return defaultEnforcer.GetPermissionAuditLog(permissionId, since, until)
+}
+
+// Check that no audit log entry of the default enforcer was modified, removed or inserted since it was written.
+// @return error
+func VerifyAuditChain() error {
+	return defaultEnforcer.VerifyAuditChain()
+}
+
+// Write the audit log of the default enforcer as JSON lines with checkpoints signed by the key.
+// @param io.Writer, ed25519.PrivateKey, int
+// @return error
+func ExportAuditLog(w io.Writer, key ed25519.PrivateKey, every int) error {
+	return defaultEnforcer.ExportAuditLog(w, key, every)
+}
+
+// Register a hook called synchronously for every event of the default enforcer, before the change commits, so it can veto it.
+// @param BeforeCommitHook
+func BeforeCommit(hook BeforeCommitHook) {
+	defaultEnforcer.BeforeCommit(hook)
+}
+
+// Register a hook called asynchronously for every event of the default enforcer once the change is committed.
+// @param AfterCommitHook
+func AfterCommit(hook AfterCommitHook) {
+	defaultEnforcer.AfterCommit(hook)
+}
+
+// Return a relay delivering the outbox of the default enforcer to the sink.
+// @param RelayOptions
+// @return *Relay
+func NewRelay(opt RelayOptions) *Relay {
+	return defaultEnforcer.NewRelay(opt)
+}
+
+// Return the outbox messages of the default enforcer having the given status, oldest first.
+// @param OutboxStatus, int
+// @return []models.Outbox, error
+func GetOutbox(status OutboxStatus, limit int) ([]models.Outbox, error) {
+	return defaultEnforcer.GetOutbox(status, limit)
+}
+
+// Deliver the given dead outbox messages of the default enforcer again.
+// @param uint
+// @return error
+func RequeueOutbox(ids ...uint) error {
+	return defaultEnforcer.RequeueOutbox(ids...)
+}
+
+// Delete the outbox messages of the default enforcer delivered before the given time.
+// @param time.Time
+// @return int64, error
+func PurgeOutbox(before time.Time) (int64, error) {
+	return defaultEnforcer.PurgeOutbox(before)
+}
+
+// Return the separator of the parts of permission names of the default enforcer.
+// @return string
+func PermissionSeparator() string {
+	return defaultEnforcer.PermissionSeparator()
+}
+
+// Assign the given roles to the user until expiresAt, when the assignments stop counting.
+// @param uint, time.Time, string
+// @return bool, error
+func AssignRolesUntil(userID uint, expiresAt time.Time, Roles ...string) (bool, error) {
+	return defaultEnforcer.AssignRolesUntil(userID, expiresAt, Roles...)
+}
+
+// Assign the given roles to the user from validFrom until expiresAt, a zero time doesn't bound the assignments.
+// @param uint, time.Time, time.Time, string
+// @return bool, error
+func AssignRolesBetween(userID uint, validFrom time.Time, expiresAt time.Time, Roles ...string) (bool, error) {
+	return defaultEnforcer.AssignRolesBetween(userID, validFrom, expiresAt, Roles...)
+}
+
+// Delete the role assignments of the default enforcer which expired and return their number.
+// @return int64, error
+func PurgeExpiredRoles() (int64, error) {
+	return defaultEnforcer.PurgeExpiredRoles()
+}
+
+// Move the role assignments of the default enforcer which expired to the archive and return their number.
+// @return int64, error
+func ArchiveExpiredRoles() (int64, error) {
+	return defaultEnforcer.ArchiveExpiredRoles()
+}
+
+// Grant the given permissions to the role until expiresAt, when the grants stop counting.
+// @param uint, time.Time, string
+// @return []models.Permission, error
+func AssignPermissionsFromRoleUntil(roleId uint, expiresAt time.Time, permissions ...string) ([]models.Permission, error) {
+	return defaultEnforcer.AssignPermissionsFromRoleUntil(roleId, expiresAt, permissions...)
+}
+
+// Grant the given permissions to the role from validFrom until expiresAt, a zero time doesn't bound the grants.
+// @param uint, time.Time, time.Time, string
+// @return []models.Permission, error
+func AssignPermissionsFromRoleBetween(roleId uint, validFrom time.Time, expiresAt time.Time, permissions ...string) ([]models.Permission, error) {
+	return defaultEnforcer.AssignPermissionsFromRoleBetween(roleId, validFrom, expiresAt, permissions...)
+}
+
+// Grant the permission on a single resource, identified by its type and ID, directly to the user.
+// @param uint, string, string, uint
+// @return bool, error
+func GrantOn(userID uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return defaultEnforcer.GrantOn(userID, permission, resourceType, resourceID)
+}
+
+// Revoke the permission on the resource granted directly to the user.
+// @param uint, string, string, uint
+// @return bool, error
+func RevokeOn(userID uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return defaultEnforcer.RevokeOn(userID, permission, resourceType, resourceID)
+}
+
+// Grant the permission on a single resource, identified by its type and ID, to the role.
+// @param uint, string, string, uint
+// @return bool, error
+func GrantRoleOn(roleId uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return defaultEnforcer.GrantRoleOn(roleId, permission, resourceType, resourceID)
+}
+
+// Revoke the permission on the resource granted to the role.
+// @param uint, string, string, uint
+// @return bool, error
+func RevokeRoleOn(roleId uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return defaultEnforcer.RevokeRoleOn(roleId, permission, resourceType, resourceID)
+}
+
+// Determine if the user has the permission on the resource, granted directly or to one of its roles.
+// @param uint, string, string, uint
+// @return bool, error
+func CanOn(userID uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return defaultEnforcer.CanOn(userID, permission, resourceType, resourceID)
+}
+
+// Return the IDs of the resources of the given type the user has the permission on.
+// @param uint, string, string
+// @return []uint, error
+func GetResourceIDs(userID uint, permission string, resourceType string) ([]uint, error) {
+	return defaultEnforcer.GetResourceIDs(userID, permission, resourceType)
+}
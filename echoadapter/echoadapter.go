@@ -0,0 +1,145 @@
+// Package echoadapter enforces grole roles and permissions on Echo routes, with
+// the decision of the middleware package.
+package echoadapter
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mousav1/grole/middleware"
+)
+
+// UserID extracts the user of a request, ok is false when the request isn't authenticated.
+type UserID func(c echo.Context) (userID uint, ok bool)
+
+// Read the user ID stored in the Echo context under key, typically by an
+// authentication middleware calling c.Set.
+// @param string
+// @return UserID
+func FromKey(key string) UserID {
+	return func(c echo.Context) (uint, bool) {
+		return middleware.ParseUserID(c.Get(key))
+	}
+}
+
+type Options struct {
+	// Middleware checks the requirements, middleware.Default() when nil.
+	Middleware *middleware.Middleware
+	// UserID extracts the user of the request, the extractor of Middleware when nil.
+	UserID UserID
+	// Deny returns the error of requests that didn't go through, an
+	// *echo.HTTPError with the status of middleware.Status by default, left to
+	// the HTTP error handler of Echo.
+	Deny func(c echo.Context, err error) error
+}
+
+// Adapter builds the Echo middlewares enforcing requirements with its options.
+type Adapter struct {
+	opts Options
+}
+
+var defaultAdapter = &Adapter{}
+
+// set the options of the adapter and make it the default one
+// @param Options
+// @return *Adapter
+func New(opt Options) *Adapter {
+	defaultAdapter = &Adapter{opts: opt}
+	return defaultAdapter
+}
+
+// Only let through users meeting the requirement.
+// @param middleware.Requirement
+// @return echo.MiddlewareFunc
+func (a *Adapter) Require(requirement middleware.Requirement) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := a.authorize(c, requirement); err != nil {
+				return a.deny(c, err)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Only let through users having at least one of the given permissions.
+// @param string
+// @return echo.MiddlewareFunc
+func (a *Adapter) RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return a.Require(middleware.AnyPermission(permissions...))
+}
+
+// Only let through users having every given permission.
+// @param string
+// @return echo.MiddlewareFunc
+func (a *Adapter) RequireAllPermissions(permissions ...string) echo.MiddlewareFunc {
+	return a.Require(middleware.AllPermissions(permissions...))
+}
+
+// Only let through users having at least one of the given roles.
+// @param string
+// @return echo.MiddlewareFunc
+func (a *Adapter) RequireAnyRole(roles ...string) echo.MiddlewareFunc {
+	return a.Require(middleware.AnyRole(roles...))
+}
+
+// Only let through users having every given role.
+// @param string
+// @return echo.MiddlewareFunc
+func (a *Adapter) RequireAllRoles(roles ...string) echo.MiddlewareFunc {
+	return a.Require(middleware.AllRoles(roles...))
+}
+
+// authorize check the requirement for the user of the request.
+func (a *Adapter) authorize(c echo.Context, requirement middleware.Requirement) error {
+	m := a.opts.Middleware
+	if m == nil {
+		m = middleware.Default()
+	}
+	if a.opts.UserID == nil {
+		return m.Authorize(c.Request(), requirement)
+	}
+	userID, ok := a.opts.UserID(c)
+	return m.Decide(c.Request().Context(), userID, ok, requirement)
+}
+
+// deny return the error of a request that didn't go through.
+func (a *Adapter) deny(c echo.Context, err error) error {
+	if a.opts.Deny != nil {
+		return a.opts.Deny(c, err)
+	}
+	return echo.NewHTTPError(middleware.Status(err)).SetInternal(err)
+}
+
+// Only let through users meeting the requirement.
+// @param middleware.Requirement
+// @return echo.MiddlewareFunc
+func Require(requirement middleware.Requirement) echo.MiddlewareFunc {
+	return defaultAdapter.Require(requirement)
+}
+
+// Only let through users having at least one of the given permissions.
+// @param string
+// @return echo.MiddlewareFunc
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return defaultAdapter.RequirePermission(permissions...)
+}
+
+// Only let through users having every given permission.
+// @param string
+// @return echo.MiddlewareFunc
+func RequireAllPermissions(permissions ...string) echo.MiddlewareFunc {
+	return defaultAdapter.RequireAllPermissions(permissions...)
+}
+
+// Only let through users having at least one of the given roles.
+// @param string
+// @return echo.MiddlewareFunc
+func RequireAnyRole(roles ...string) echo.MiddlewareFunc {
+	return defaultAdapter.RequireAnyRole(roles...)
+}
+
+// Only let through users having every given role.
+// @param string
+// @return echo.MiddlewareFunc
+func RequireAllRoles(roles ...string) echo.MiddlewareFunc {
+	return defaultAdapter.RequireAllRoles(roles...)
+}
@@ -0,0 +1,119 @@
+package echoadapter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/labstack/echo/v4"
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/echoadapter"
+	"github.com/mousav1/grole/middleware"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// enforcer return an enforcer on an in-memory database, without grole.New.
+func enforcer(t *testing.T) *grole.Enforcer {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return grole.NewEnforcer(grole.Options{DB: db})
+}
+
+// userIs is a requirement only met by the given user.
+func userIs(userID uint) middleware.Requirement {
+	return func(e *grole.Enforcer, user uint) (bool, error) {
+		return user == userID, nil
+	}
+}
+
+func TestRequire(t *testing.T) {
+	adapter := echoadapter.New(echoadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{
+			Enforcer: enforcer(t),
+			UserID:   middleware.FromHeader("X-User-ID"),
+		}),
+	})
+
+	router := echo.New()
+	router.GET("/invoices", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, adapter.Require(userIs(1)))
+
+	for userID, status := range map[string]int{
+		"1": http.StatusNoContent,
+		"2": http.StatusForbidden,
+		"":  http.StatusUnauthorized,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+		r.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		require.Equal(t, status, w.Code, userID)
+	}
+}
+
+func TestNoEnforcer(t *testing.T) {
+	adapter := echoadapter.New(echoadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{UserID: middleware.FromHeader("X-User-ID")}),
+	})
+
+	var failures []error
+	router := echo.New()
+	router.HTTPErrorHandler = func(err error, c echo.Context) {
+		failures = append(failures, err)
+		router.DefaultHTTPErrorHandler(err, c)
+	}
+	router.GET("/invoices", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, adapter.Require(userIs(1)))
+
+	r := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+	r.Header.Set("X-User-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	require.Len(t, failures, 1)
+	var httpError *echo.HTTPError
+	require.ErrorAs(t, failures[0], &httpError)
+	require.ErrorIs(t, httpError.Internal, middleware.ErrNoEnforcer)
+}
+
+func TestFromKey(t *testing.T) {
+	var denied error
+	adapter := echoadapter.New(echoadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{Enforcer: enforcer(t)}),
+		UserID:     echoadapter.FromKey("user_id"),
+		Deny: func(c echo.Context, err error) error {
+			denied = err
+			return c.NoContent(http.StatusTeapot)
+		},
+	})
+
+	router := echo.New()
+	router.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", uint(2))
+			return next(c)
+		}
+	})
+	router.GET("/invoices", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, adapter.Require(userIs(2)))
+	router.GET("/refunds", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, adapter.Require(userIs(1)))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/invoices", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.NoError(t, denied)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/refunds", nil))
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.ErrorIs(t, denied, middleware.ErrForbidden)
+}
@@ -0,0 +1,79 @@
+package grole
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Entity is the kind of record an Error refers to.
+type Entity string
+
+const (
+	EntityRole       Entity = "role"
+	EntityPermission Entity = "permission"
+	EntityUser       Entity = "user"
+	EntityAuditLog   Entity = "audit log"
+)
+
+var (
+	ErrRoleNotFound           = errors.New("ROLE NOT FOUND")
+	ErrPermissionNotFound     = errors.New("PERMISSION NOT FOUND")
+	ErrRoleAssigned           = errors.New("ROLE IS ASSIGNED")
+	ErrPermissionAssigned     = errors.New("PERMISSION IS ASSIGNED")
+	ErrRoleNotAssigned        = errors.New("ROLE IS NOT ASSIGNED")
+	ErrPermissionNotAssigned  = errors.New("PERMISSION IS NOT ASSIGNED")
+	ErrRoleHierarchyCycle     = errors.New("ROLE HIERARCHY CYCLE")
+	ErrInvalidPermissionName  = errors.New("INVALID PERMISSION NAME")
+	ErrInvalidValidity        = errors.New("INVALID VALIDITY")
+	ErrAuditEntryModified     = errors.New("AUDIT ENTRY IS MODIFIED")
+	ErrAuditChainGap          = errors.New("AUDIT CHAIN HAS A GAP")
+	ErrAuditCheckpointInvalid = errors.New("AUDIT CHECKPOINT IS INVALID")
+)
+
+// Error is returned by every operation that fails on a specific entity.
+// It can be inspected with errors.Is against the sentinel errors above (or the
+// underlying gorm error) and with errors.As to read the entity and its key.
+type Error struct {
+	Entity Entity
+	Key    interface{}
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s %v", e.Err, e.Entity, e.Key)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError create an Error for the given entity and key.
+func newError(entity Entity, key interface{}, err error) error {
+	return &Error{Entity: entity, Key: key, Err: err}
+}
+
+// wrapError convert a gorm error to an Error, record not found errors are
+// replaced by the sentinel error of the entity.
+func wrapError(entity Entity, key interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		switch entity {
+		case EntityRole:
+			err = ErrRoleNotFound
+		case EntityPermission:
+			err = ErrPermissionNotFound
+		}
+	}
+	return newError(entity, key, err)
+}
+
+// reportError pass an error of background work to Options.OnError.
+func (e *Enforcer) reportError(err error) {
+	if err != nil && e.opts.OnError != nil {
+		e.opts.OnError(err)
+	}
+}
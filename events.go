@@ -0,0 +1,241 @@
+package grole
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mousav1/grole/models"
+)
+
+// Event is a change made by a grole operation, one of the event types below.
+// Hooks tell them apart with a type switch.
+type Event interface {
+	// Info return the action, actor and scope of the event.
+	Info() EventInfo
+	info() *EventInfo
+}
+
+// EventInfo is embedded in every event.
+type EventInfo struct {
+	Action Action
+	// Actor made the change, empty when the enforcer has none.
+	Actor string
+	// Scope of the enforcer making the change.
+	Scope string
+}
+
+// Return the action, actor and scope of the event.
+// @return EventInfo
+func (i EventInfo) Info() EventInfo {
+	return i
+}
+
+func (i *EventInfo) info() *EventInfo {
+	return i
+}
+
+// RoleCreated is fired when a role is created.
+type RoleCreated struct {
+	EventInfo
+	Role models.Role
+}
+
+// RoleUpdated is fired when the name or description of a role changes.
+type RoleUpdated struct {
+	EventInfo
+	Before models.Role
+	After  models.Role
+}
+
+// RoleDeleted is fired when a role is deleted.
+type RoleDeleted struct {
+	EventInfo
+	Role models.Role
+}
+
+// PermissionCreated is fired when a permission is created.
+type PermissionCreated struct {
+	EventInfo
+	Permission models.Permission
+}
+
+// PermissionUpdated is fired when the name or description of a permission changes.
+type PermissionUpdated struct {
+	EventInfo
+	Before models.Permission
+	After  models.Permission
+}
+
+// PermissionDeleted is fired when a permission is deleted.
+type PermissionDeleted struct {
+	EventInfo
+	Permission models.Permission
+}
+
+// RoleAssigned is fired when a role is assigned to a user, or when the
+// validity of the assignment changes. Nil bounds don't limit it.
+type RoleAssigned struct {
+	EventInfo
+	UserID    uint
+	Role      models.Role
+	ValidFrom *time.Time
+	ExpiresAt *time.Time
+}
+
+// RoleRevoked is fired when a role is revoked from a user.
+type RoleRevoked struct {
+	EventInfo
+	UserID uint
+	Role   models.Role
+}
+
+// RoleExpired is fired when an expired role assignment is removed by
+// PurgeExpiredRoles or ArchiveExpiredRoles.
+type RoleExpired struct {
+	EventInfo
+	UserID    uint
+	Role      models.Role
+	ExpiresAt time.Time
+}
+
+// PermissionGranted is fired when a permission is granted to a role, or
+// directly to a user. Role is zero for a user and UserID is zero for a role.
+// It is also fired when the validity of a grant to a role changes, nil bounds
+// don't limit it.
+type PermissionGranted struct {
+	EventInfo
+	UserID     uint
+	Role       models.Role
+	Permission models.Permission
+	ValidFrom  *time.Time
+	ExpiresAt  *time.Time
+}
+
+// PermissionRevoked is fired when a permission is revoked from a role, or
+// directly from a user. Role is zero for a user and UserID is zero for a role.
+type PermissionRevoked struct {
+	EventInfo
+	UserID     uint
+	Role       models.Role
+	Permission models.Permission
+}
+
+// ParentRoleAdded is fired when a role starts inheriting from a parent role.
+type ParentRoleAdded struct {
+	EventInfo
+	Role   models.Role
+	Parent models.Role
+}
+
+// ParentRoleRemoved is fired when a role stops inheriting from a parent role.
+type ParentRoleRemoved struct {
+	EventInfo
+	Role   models.Role
+	Parent models.Role
+}
+
+// ResourceGranted is fired when a permission on a single resource is granted
+// to a user or a role. Role is zero for a user and UserID is zero for a role.
+type ResourceGranted struct {
+	EventInfo
+	UserID       uint
+	Role         models.Role
+	Permission   models.Permission
+	ResourceType string
+	ResourceID   uint
+}
+
+// ResourceRevoked is fired when a permission on a single resource is revoked
+// from a user or a role. Role is zero for a user and UserID is zero for a role.
+type ResourceRevoked struct {
+	EventInfo
+	UserID       uint
+	Role         models.Role
+	Permission   models.Permission
+	ResourceType string
+	ResourceID   uint
+}
+
+// BeforeCommitHook is called in the transaction making the change, before it
+// commits. Returning an error vetoes the change: the transaction is rolled
+// back and the operation returns the error. Grole operations called on tx
+// are part of the same transaction, and fire their own events.
+type BeforeCommitHook func(tx *Tx, event Event) error
+
+// AfterCommitHook is called in the background once the change is committed.
+type AfterCommitHook func(event Event)
+
+// hooks is shared by an enforcer and its copies.
+type hooks struct {
+	mu     sync.RWMutex
+	before []BeforeCommitHook
+	after  []AfterCommitHook
+}
+
+// Register a hook called synchronously for every event, in the transaction
+// making the change and before it commits, so it can veto it.
+// @param BeforeCommitHook
+func (e *Enforcer) BeforeCommit(hook BeforeCommitHook) {
+	e.hooks.mu.Lock()
+	defer e.hooks.mu.Unlock()
+	e.hooks.before = append(e.hooks.before, hook)
+}
+
+// Register a hook called asynchronously for every event once the change is
+// committed. The events of a transaction are delivered in order, panics are
+// reported to Options.OnError.
+// @param AfterCommitHook
+func (e *Enforcer) AfterCommit(hook AfterCommitHook) {
+	e.hooks.mu.Lock()
+	defer e.hooks.mu.Unlock()
+	e.hooks.after = append(e.hooks.after, hook)
+}
+
+// beforeCommit pass the changes recorded in the transaction to the before
+// commit hooks, including the ones the hooks make.
+func (e *Enforcer) beforeCommit(tx *Enforcer) error {
+	e.hooks.mu.RLock()
+	before := e.hooks.before
+	e.hooks.mu.RUnlock()
+	if len(before) == 0 {
+		return nil
+	}
+	for i := 0; i < len(tx.txState.changes); i++ {
+		event := tx.txState.changes[i].event
+		for _, hook := range before {
+			if err := hook(&Tx{Enforcer: tx}, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// afterCommit pass the committed changes to the after commit hooks in a new
+// goroutine.
+func (e *Enforcer) afterCommit(changes []change) {
+	e.hooks.mu.RLock()
+	after := e.hooks.after
+	e.hooks.mu.RUnlock()
+	if len(after) == 0 {
+		return
+	}
+	go func() {
+		for _, c := range changes {
+			for _, hook := range after {
+				e.callAfterCommit(hook, c.event)
+			}
+		}
+	}()
+}
+
+// callAfterCommit call the hook, reporting its panic instead of crashing.
+func (e *Enforcer) callAfterCommit(hook AfterCommitHook, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.reportError(fmt.Errorf("grole: after commit hook panicked: %v", r))
+		}
+	}()
+	hook(event)
+}
@@ -0,0 +1,45 @@
+package gate
+
+import (
+	"context"
+)
+
+// Define the ability with the given name on the default gate.
+// @param string, Ability
+func Define(name string, ability Ability) {
+	defaultGate.Define(name, ability)
+}
+
+// Determine if the ability with the given name is defined on the default gate.
+// @param string
+// @return bool
+func Has(name string) bool {
+	return defaultGate.Has(name)
+}
+
+// Determine if the user is allowed the ability with the given arguments.
+// @param context.Context, uint, string, ...interface{}
+// @return bool, error
+func Allows(ctx context.Context, userID uint, ability string, args ...interface{}) (bool, error) {
+	return defaultGate.Allows(ctx, userID, ability, args...)
+}
+
+// Determine if the user is denied the ability with the given arguments.
+// @param context.Context, uint, string, ...interface{}
+// @return bool, error
+func Denies(ctx context.Context, userID uint, ability string, args ...interface{}) (bool, error) {
+	return defaultGate.Denies(ctx, userID, ability, args...)
+}
+
+// Return nil when the user is allowed the ability, an *AuthorizationError otherwise.
+// @param context.Context, uint, string, ...interface{}
+// @return error
+func Authorize(ctx context.Context, userID uint, ability string, args ...interface{}) error {
+	return defaultGate.Authorize(ctx, userID, ability, args...)
+}
+
+// Register the policy of the model type on the default gate.
+// @param interface{}, interface{}
+func Policy(model interface{}, policy interface{}) {
+	defaultGate.Policy(model, policy)
+}
@@ -0,0 +1,147 @@
+// Package gate authorizes abilities defined as Go closures, for the rules
+// which aren't pure roles and permissions, like "a user can update a post if
+// they own it or have posts.moderate".
+package gate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/mousav1/grole"
+)
+
+var (
+	ErrAbilityNotDefined = errors.New("ABILITY NOT DEFINED")
+	ErrNoEnforcer        = errors.New("NO ENFORCER")
+)
+
+// Ability tells whether the user may perform it with the given arguments,
+// typically the resource acted on. An error means it couldn't be checked.
+type Ability func(ctx context.Context, user User, args ...interface{}) (bool, error)
+
+// AuthorizationError is returned by Authorize when the user isn't allowed
+// the ability.
+type AuthorizationError struct {
+	Ability string
+	UserID  uint
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("UNAUTHORIZED: user %d %s", e.UserID, e.Ability)
+}
+
+type Options struct {
+	// Enforcer is passed to the abilities through the user, the default
+	// enforcer of grole when nil.
+	Enforcer *grole.Enforcer
+}
+
+// Gate holds the abilities and the policies of the models, and checks them.
+type Gate struct {
+	opts      Options
+	mu        sync.RWMutex
+	abilities map[string]Ability
+	policies  map[reflect.Type]reflect.Value
+}
+
+var defaultGate = NewGate(Options{})
+
+// set the options of the gate and make it the default one
+// @param Options
+// @return *Gate
+func New(opt Options) *Gate {
+	defaultGate = NewGate(opt)
+	return defaultGate
+}
+
+// create a new gate without changing the default one
+// @param Options
+// @return *Gate
+func NewGate(opt Options) *Gate {
+	return &Gate{opts: opt, abilities: make(map[string]Ability), policies: make(map[reflect.Type]reflect.Value)}
+}
+
+// Return the default gate, used by the package level functions.
+// @return *Gate
+func Default() *Gate {
+	return defaultGate
+}
+
+// Define the ability with the given name, replacing any previous one.
+// @param string, Ability
+func (g *Gate) Define(name string, ability Ability) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.abilities[name] = ability
+}
+
+// Determine if the ability with the given name is defined.
+// @param string
+// @return bool
+func (g *Gate) Has(name string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.abilities[name]
+	return ok
+}
+
+// Determine if the user is allowed the ability with the given arguments. An
+// ability without closure is checked on the model given as first argument,
+// see Policy, and returns ErrAbilityNotDefined without model.
+// @param context.Context, uint, string, ...interface{}
+// @return bool, error
+func (g *Gate) Allows(ctx context.Context, userID uint, ability string, args ...interface{}) (bool, error) {
+	g.mu.RLock()
+	check, ok := g.abilities[ability]
+	g.mu.RUnlock()
+	user := g.user(ctx, userID)
+	if ok {
+		return check(ctx, user, args...)
+	}
+	allowed, ok, err := g.checkModel(ctx, user, ability, args)
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrAbilityNotDefined, ability)
+	}
+	return allowed, err
+}
+
+// Determine if the user is denied the ability with the given arguments.
+// @param context.Context, uint, string, ...interface{}
+// @return bool, error
+func (g *Gate) Denies(ctx context.Context, userID uint, ability string, args ...interface{}) (bool, error) {
+	allowed, err := g.Allows(ctx, userID, ability, args...)
+	if err != nil {
+		return false, err
+	}
+	return !allowed, nil
+}
+
+// Return nil when the user is allowed the ability with the given arguments,
+// an *AuthorizationError when they aren't, or the error preventing the check.
+// @param context.Context, uint, string, ...interface{}
+// @return error
+func (g *Gate) Authorize(ctx context.Context, userID uint, ability string, args ...interface{}) error {
+	allowed, err := g.Allows(ctx, userID, ability, args...)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return &AuthorizationError{Ability: ability, UserID: userID}
+	}
+	return nil
+}
+
+// user return the user passed to the abilities, bound to the context.
+func (g *Gate) user(ctx context.Context, userID uint) User {
+	enforcer := g.opts.Enforcer
+	if enforcer == nil {
+		enforcer = grole.Default()
+	}
+	if enforcer != nil {
+		enforcer = enforcer.WithContext(ctx)
+	}
+	return User{ID: userID, enforcer: enforcer}
+}
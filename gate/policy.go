@@ -0,0 +1,157 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidPolicy = errors.New("INVALID POLICY")
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	userType    = reflect.TypeOf(User{})
+	boolType    = reflect.TypeOf(false)
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Register the policy of the model type, given as a value or a pointer. An
+// ability checked with a model of that type as first argument, and defined
+// by no closure, calls the policy method named after it: "update" calls
+// Update and "force-delete" calls ForceDelete. The methods take the context,
+// the user and the arguments, and return (bool, error), e.g.
+//
+//	func (ArticlePolicy) Update(ctx context.Context, user gate.User, article *Article) (bool, error)
+//
+// Without a policy method, the user needs the permission named after the
+// model type and the ability, "article.update" for an *Article, joined with
+// the separator of the enforcer. Policy panics when an exported method of the
+// policy has another signature.
+// @param interface{}, interface{}
+func (g *Gate) Policy(model interface{}, policy interface{}) {
+	if model == nil || policy == nil {
+		panic("gate: nil model or policy")
+	}
+	value := reflect.ValueOf(policy)
+	for i := 0; i < value.NumMethod(); i++ {
+		if !validPolicyMethod(value.Method(i).Type()) {
+			panic(fmt.Errorf("gate: %w: %s.%s", ErrInvalidPolicy, value.Type(), value.Type().Method(i).Name))
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.policies[modelType(model)] = value
+}
+
+// validPolicyMethod tell whether the method takes the context, the user and
+// at least one argument, and returns (bool, error).
+func validPolicyMethod(mt reflect.Type) bool {
+	return mt.NumIn() >= 3 && mt.In(0) == contextType && mt.In(1) == userType &&
+		mt.NumOut() == 2 && mt.Out(0) == boolType && mt.Out(1) == errorType
+}
+
+// checkModel check the ability on the model given as first argument, with
+// its policy method or its permission. ok is false when args has no model.
+func (g *Gate) checkModel(ctx context.Context, user User, ability string, args []interface{}) (allowed bool, ok bool, err error) {
+	if len(args) == 0 || args[0] == nil {
+		return false, false, nil
+	}
+	t := modelType(args[0])
+	if t.Kind() != reflect.Struct {
+		return false, false, nil
+	}
+
+	g.mu.RLock()
+	policy, registered := g.policies[t]
+	g.mu.RUnlock()
+	if registered {
+		if method := policy.MethodByName(methodName(ability)); method.IsValid() {
+			allowed, err := callPolicy(method, ctx, user, args)
+			if err != nil {
+				return false, true, fmt.Errorf("%w: %s.%s", err, policy.Type(), methodName(ability))
+			}
+			return allowed, true, nil
+		}
+	}
+	if user.Enforcer() == nil {
+		return false, true, ErrNoEnforcer
+	}
+	allowed, err = user.HasAnyPermissions(permissionName(t, ability, user.Enforcer().PermissionSeparator()))
+	return allowed, true, err
+}
+
+// callPolicy call the policy method with the context, the user and the
+// arguments, ErrInvalidPolicy when they don't match its parameters.
+func callPolicy(method reflect.Value, ctx context.Context, user User, args []interface{}) (bool, error) {
+	mt := method.Type()
+	if mt.NumIn() != len(args)+2 {
+		return false, ErrInvalidPolicy
+	}
+	in := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(user)}
+	for i, arg := range args {
+		want := mt.In(i + 2)
+		if arg == nil {
+			switch want.Kind() {
+			case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+				in = append(in, reflect.Zero(want))
+				continue
+			}
+			return false, ErrInvalidPolicy
+		}
+		value := reflect.ValueOf(arg)
+		if !value.Type().AssignableTo(want) {
+			return false, ErrInvalidPolicy
+		}
+		in = append(in, value)
+	}
+	out := method.Call(in)
+	err, _ := out[1].Interface().(error)
+	return out[0].Bool(), err
+}
+
+// modelType return the type of the model, pointers dereferenced.
+func modelType(model interface{}) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// methodName return the policy method of the ability, "force-delete" is
+// ForceDelete.
+func methodName(ability string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range ability {
+		if r == '-' || r == '_' || r == '.' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+// permissionName return the grole permission of the ability on the model
+// type, "blog_post.update" for a BlogPost with the "." separator.
+func permissionName(t reflect.Type, ability string, separator string) string {
+	var name strings.Builder
+	for i, r := range t.Name() {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	return name.String() + separator + ability
+}
@@ -0,0 +1,68 @@
+package gate
+
+import (
+	"github.com/mousav1/grole"
+)
+
+// User is the user an ability is checked for, its methods ask grole about its
+// roles and permissions with the context of the check.
+type User struct {
+	ID       uint
+	enforcer *grole.Enforcer
+}
+
+// Return the enforcer of the user, nil when the gate has none.
+// @return *grole.Enforcer
+func (u User) Enforcer() *grole.Enforcer {
+	return u.enforcer
+}
+
+// Determine if the user has at least one of the given permissions.
+// @param string
+// @return bool, error
+func (u User) HasAnyPermissions(permissions ...string) (bool, error) {
+	if u.enforcer == nil {
+		return false, ErrNoEnforcer
+	}
+	return u.enforcer.HasAnyPermissions(u.ID, permissions...)
+}
+
+// Determine if the user has every given permission.
+// @param string
+// @return bool, error
+func (u User) HasAllPermissions(permissions ...string) (bool, error) {
+	if u.enforcer == nil {
+		return false, ErrNoEnforcer
+	}
+	return u.enforcer.HasAllPermission(u.ID, permissions...)
+}
+
+// Determine if the user has at least one of the given roles.
+// @param string
+// @return bool, error
+func (u User) HasAnyRole(roles ...string) (bool, error) {
+	if u.enforcer == nil {
+		return false, ErrNoEnforcer
+	}
+	return u.enforcer.HasAnyRole(u.ID, roles...)
+}
+
+// Determine if the user has every given role.
+// @param string
+// @return bool, error
+func (u User) HasAllRoles(roles ...string) (bool, error) {
+	if u.enforcer == nil {
+		return false, ErrNoEnforcer
+	}
+	return u.enforcer.HasAllRole(u.ID, roles...)
+}
+
+// Determine if the user has the permission on the resource.
+// @param string, string, uint
+// @return bool, error
+func (u User) CanOn(permission string, resourceType string, resourceID uint) (bool, error) {
+	if u.enforcer == nil {
+		return false, ErrNoEnforcer
+	}
+	return u.enforcer.CanOn(u.ID, permission, resourceType, resourceID)
+}
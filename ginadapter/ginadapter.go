@@ -0,0 +1,153 @@
+// Package ginadapter enforces grole roles and permissions on Gin routes, with
+// the decision of the middleware package.
+package ginadapter
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mousav1/grole/middleware"
+)
+
+// UserID extracts the user of a request, ok is false when the request isn't authenticated.
+type UserID func(c *gin.Context) (userID uint, ok bool)
+
+// Read the user ID stored in the Gin context under key, typically by an
+// authentication middleware calling c.Set.
+// @param string
+// @return UserID
+func FromKey(key string) UserID {
+	return func(c *gin.Context) (uint, bool) {
+		value, _ := c.Get(key)
+		return middleware.ParseUserID(value)
+	}
+}
+
+type Options struct {
+	// Middleware checks the requirements, middleware.Default() when nil.
+	Middleware *middleware.Middleware
+	// UserID extracts the user of the request, the extractor of Middleware when nil.
+	UserID UserID
+	// Deny responds to requests that didn't go through, it aborts with the
+	// status of middleware.Status by default and records grole errors in c.Errors.
+	Deny func(c *gin.Context, err error)
+}
+
+// Adapter builds the Gin handlers enforcing requirements with its options.
+type Adapter struct {
+	opts Options
+}
+
+var defaultAdapter = &Adapter{}
+
+// set the options of the adapter and make it the default one
+// @param Options
+// @return *Adapter
+func New(opt Options) *Adapter {
+	defaultAdapter = &Adapter{opts: opt}
+	return defaultAdapter
+}
+
+// Only let through users meeting the requirement.
+// @param middleware.Requirement
+// @return gin.HandlerFunc
+func (a *Adapter) Require(requirement middleware.Requirement) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := a.authorize(c, requirement); err != nil {
+			a.deny(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// Only let through users having at least one of the given permissions.
+// @param string
+// @return gin.HandlerFunc
+func (a *Adapter) RequirePermission(permissions ...string) gin.HandlerFunc {
+	return a.Require(middleware.AnyPermission(permissions...))
+}
+
+// Only let through users having every given permission.
+// @param string
+// @return gin.HandlerFunc
+func (a *Adapter) RequireAllPermissions(permissions ...string) gin.HandlerFunc {
+	return a.Require(middleware.AllPermissions(permissions...))
+}
+
+// Only let through users having at least one of the given roles.
+// @param string
+// @return gin.HandlerFunc
+func (a *Adapter) RequireAnyRole(roles ...string) gin.HandlerFunc {
+	return a.Require(middleware.AnyRole(roles...))
+}
+
+// Only let through users having every given role.
+// @param string
+// @return gin.HandlerFunc
+func (a *Adapter) RequireAllRoles(roles ...string) gin.HandlerFunc {
+	return a.Require(middleware.AllRoles(roles...))
+}
+
+// authorize check the requirement for the user of the request.
+func (a *Adapter) authorize(c *gin.Context, requirement middleware.Requirement) error {
+	m := a.opts.Middleware
+	if m == nil {
+		m = middleware.Default()
+	}
+	if a.opts.UserID == nil {
+		return m.Authorize(c.Request, requirement)
+	}
+	userID, ok := a.opts.UserID(c)
+	return m.Decide(c.Request.Context(), userID, ok, requirement)
+}
+
+// deny respond to a request that didn't go through.
+func (a *Adapter) deny(c *gin.Context, err error) {
+	if a.opts.Deny != nil {
+		a.opts.Deny(c, err)
+		c.Abort()
+		return
+	}
+	status := middleware.Status(err)
+	if status == http.StatusInternalServerError {
+		c.AbortWithError(status, err)
+		return
+	}
+	c.AbortWithStatus(status)
+}
+
+// Only let through users meeting the requirement.
+// @param middleware.Requirement
+// @return gin.HandlerFunc
+func Require(requirement middleware.Requirement) gin.HandlerFunc {
+	return defaultAdapter.Require(requirement)
+}
+
+// Only let through users having at least one of the given permissions.
+// @param string
+// @return gin.HandlerFunc
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return defaultAdapter.RequirePermission(permissions...)
+}
+
+// Only let through users having every given permission.
+// @param string
+// @return gin.HandlerFunc
+func RequireAllPermissions(permissions ...string) gin.HandlerFunc {
+	return defaultAdapter.RequireAllPermissions(permissions...)
+}
+
+// Only let through users having at least one of the given roles.
+// @param string
+// @return gin.HandlerFunc
+func RequireAnyRole(roles ...string) gin.HandlerFunc {
+	return defaultAdapter.RequireAnyRole(roles...)
+}
+
+// Only let through users having every given role.
+// @param string
+// @return gin.HandlerFunc
+func RequireAllRoles(roles ...string) gin.HandlerFunc {
+	return defaultAdapter.RequireAllRoles(roles...)
+}
@@ -0,0 +1,115 @@
+package ginadapter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/ginadapter"
+	"github.com/mousav1/grole/middleware"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// enforcer return an enforcer on an in-memory database, without grole.New.
+func enforcer(t *testing.T) *grole.Enforcer {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return grole.NewEnforcer(grole.Options{DB: db})
+}
+
+// userIs is a requirement only met by the given user.
+func userIs(userID uint) middleware.Requirement {
+	return func(e *grole.Enforcer, user uint) (bool, error) {
+		return user == userID, nil
+	}
+}
+
+func TestRequire(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adapter := ginadapter.New(ginadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{
+			Enforcer: enforcer(t),
+			UserID:   middleware.FromHeader("X-User-ID"),
+		}),
+	})
+
+	router := gin.New()
+	router.GET("/invoices", adapter.Require(userIs(1)), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for userID, status := range map[string]int{
+		"1": http.StatusNoContent,
+		"2": http.StatusForbidden,
+		"":  http.StatusUnauthorized,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+		r.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		require.Equal(t, status, w.Code, userID)
+	}
+}
+
+func TestNoEnforcer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adapter := ginadapter.New(ginadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{UserID: middleware.FromHeader("X-User-ID")}),
+	})
+
+	var failures []string
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		failures = append(failures, c.Errors.Errors()...)
+	})
+	router.GET("/invoices", adapter.Require(userIs(1)), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+	r.Header.Set("X-User-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, []string{middleware.ErrNoEnforcer.Error()}, failures)
+}
+
+func TestFromKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var denied error
+	adapter := ginadapter.New(ginadapter.Options{
+		Middleware: middleware.NewMiddleware(middleware.Options{Enforcer: enforcer(t)}),
+		UserID:     ginadapter.FromKey("user_id"),
+		Deny: func(c *gin.Context, err error) {
+			denied = err
+			c.AbortWithStatus(http.StatusTeapot)
+		},
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 2)
+	})
+	router.GET("/invoices", adapter.Require(userIs(2)), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/refunds", adapter.Require(userIs(1)), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/invoices", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.NoError(t, denied)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/refunds", nil))
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.ErrorIs(t, denied, middleware.ErrForbidden)
+}
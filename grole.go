@@ -1,870 +1,1132 @@
-package grole
-
-import (
-	"errors"
-
-	"github.com/mousav1/grole/migrate"
-	"github.com/mousav1/grole/models"
-	"gorm.io/gorm"
-)
-
-type Options struct {
-	DB *gorm.DB
-}
-
-var conn *models.Database
-
-// set database connection
-func New(opt Options) *models.Database {
-	conn = models.Initializers(opt.DB)
-	migrate.MigrateTables(opt.DB)
-	return conn
-}
-
-// delete the given role
-// @param uint
-// @return bool, error
-func DeleteRole(roleId uint) (bool, error) {
-
-	var userRole models.UserRoles
-	res := conn.DB.Where("role_id = ?", roleId).First(&userRole)
-	if res.Error == nil {
-		return false, errors.New("ROLE IS ASSIGNED")
-	}
-
-	res = conn.DB.Where("id = ?", roleId).Delete(&models.Role{})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE DELETED BECAUSE IT DOESN'T EXIST")
-	}
-	return true, nil
-}
-
-// update the given role
-// @param uint
-// @return bool, error
-func UpdateRole(roleId uint, newRole models.Role) (bool, error) {
-	res := conn.DB.Where("id = ?", roleId).Updates(models.Role{Name: newRole.Name, Description: newRole.Description})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE UPDATE BECAUSE IT DOESN'T EXIST")
-	}
-	return true, nil
-}
-
-// delete the given permission
-// @param uint
-// @return bool, error
-func DeletePermission(permissionId uint) (bool, error) {
-	var permission models.Permission
-
-	res := conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-	}
-
-	roleCount := conn.DB.Model(&permission).Association("Roles").Count()
-	if roleCount > 0 {
-		return false, errors.New("PERMISSION IS ASSIGNED")
-	}
-
-	res = conn.DB.Where("id = ?", permissionId).Delete(&models.Permission{})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE DELETED BECAUSE IT DOESN'T EXIST")
-	}
-
-	return true, nil
-}
-
-// update the given permission
-// @param uint
-// @return bool, error
-func UpdatePermission(permissionId uint, newPermission models.Permission) (bool, error) {
-	res := conn.DB.Where("id = ?", permissionId).Updates(models.Permission{Name: newPermission.Name, Description: newPermission.Description})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE UPDATE BECAUSE IT DOESN'T EXIST")
-	}
-	return true, nil
-}
-
-// Show All Permission With Role
-// @return []models.Permission, error
-func FindAllPermission() ([]models.Permission, error) {
-	var permissions []models.Permission
-	res := conn.DB.Preload("Roles").Find(&permissions)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("RECORD NOT FOUND")
-		} else {
-			return nil, res.Error
-		}
-	}
-	return permissions, nil
-}
-
-// Find roles of each permission
-// @param string
-// @return []models.Role
-func Roles(permissions ...string) ([]models.Role, error) {
-	var roles []models.Role
-	var allRole []models.Role
-	for _, permission := range permissions {
-		per, err := FindPermissionByName(permission)
-		if err != nil {
-			return nil, errors.New("PERMISSION DOESN'T EXIST")
-		}
-		conn.DB.Model(&per).Association("Roles").Find(&roles)
-		allRole = append(allRole, roles...)
-	}
-	return allRole, nil
-}
-
-// find Permission By Name and Show each with Role
-// @param string
-// @return models.Permission, error
-func FindPermissionByName(name string) (models.Permission, error) {
-	var permission models.Permission
-	res := conn.DB.Where("name = ?", name).Preload("Roles").First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return permission, errors.New("PERMISSION NOT FOUND")
-		}
-	}
-	return permission, nil
-}
-
-// find Permission By Id and Show each with Role
-// @param uint
-// @return models.Permission, error
-func FindPermissionById(id uint) (models.Permission, error) {
-	var permission models.Permission
-	res := conn.DB.Where("id = ?", id).Preload("Roles").First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return permission, errors.New("RECORD NOT FOUND")
-		}
-		return permission, res.Error
-	}
-	return permission, nil
-}
-
-// find Permission or Create Permission If not found
-// @param models.Permission
-// @return models.Permission, error
-func FindOrCreatePermission(permission models.Permission) (models.Permission, error) {
-	var newPermission models.Permission
-	res := conn.DB.FirstOrCreate(&newPermission, permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return newPermission, errors.New("RECORD NOT FOUND")
-		}
-		if res.RowsAffected < 1 {
-			return newPermission, errors.New("CANNOT BE INSERT OR CREATE PERMISSION ")
-		}
-		return newPermission, res.Error
-	}
-	return newPermission, nil
-}
-
-// Revoke the given role by id for permission
-// @param uint, uint
-// @return bool, error
-func RemoveRoleByIdFromPermission(permissionId uint, roleId uint) (bool, error) {
-	var role models.Role
-	var permission models.Permission
-
-	res := conn.DB.Where("id = ?", roleId).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("ROLE NOT FOUND")
-		}
-	}
-	res = conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("PERMISSION NOT FOUND")
-		}
-	}
-
-	error := conn.DB.Model(&permission).Association("Roles").Delete(&role)
-	if error != nil {
-		return false, error
-	}
-	return true, nil
-}
-
-// Revoke the given role by name for permission
-// @param uint, string
-// @return bool, error
-func RemoveRoleByNameFromPermission(permissionId uint, roleName string) (bool, error) {
-	var role models.Role
-	var permission models.Permission
-
-	res := conn.DB.Where("name = ?", roleName).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("ROLE NOT FOUND")
-		}
-	}
-	res = conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("PERMISSSION NOT FOUND")
-		}
-	}
-
-	error := conn.DB.Model(&permission).Association("Roles").Delete(&role)
-	if error != nil {
-		return false, error
-	}
-	return true, nil
-}
-
-// Revoke the given role for permission
-// @param uint, string
-// @return bool, error
-func RemoveRoleFromPermission(permissionId uint, roleName string) (bool, error) {
-	var permission models.Permission
-
-	res := conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-	}
-
-	findRole, err := FindRoleByName(roleName)
-	if err != nil {
-		return false, errors.New("ROLE DOESN'T EXIST")
-	}
-
-	error := conn.DB.Model(&permission).Association("Roles").Delete(&findRole)
-	if error != nil {
-		return false, error
-	}
-	return true, nil
-}
-
-// Return the number of permissions Role.
-// @param uint
-// @return int64, error
-func CountRoleFromPermission(permissionId uint) (int64, error) {
-	var permission models.Permission
-
-	res := conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return 0, errors.New("RECORD NOT FOUND")
-		}
-	}
-
-	return conn.DB.Model(&permission).Association("Roles").Count(), nil
-}
-
-// Remove all current Role for Permission.
-// @param uint
-// @return bool, error
-func RemoveAllRoleFromPermission(permissionId uint) (bool, error) {
-	var permission models.Permission
-
-	res := conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-	}
-
-	error := conn.DB.Model(&permission).Association("Roles").Clear()
-	if error != nil {
-		return false, error
-	}
-
-	return true, nil
-}
-
-// Remove all current Permission role and set the given ones.
-// @param uint, string
-// @return []models.Role, error
-func SyncRolesFromPermission(permissionId uint, roles ...string) ([]models.Role, error) {
-	var permission models.Permission
-	rolesModel := []models.Role{}
-
-	res := conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("RECORD NOT FOUND")
-		}
-	}
-
-	for _, roleName := range roles {
-		role, err := FindRoleByName(roleName)
-		if err != nil {
-			return rolesModel, errors.New("ROLE DOESN'T EXIST")
-		} else {
-			rolesModel = append(rolesModel, role)
-		}
-	}
-
-	error := conn.DB.Model(&permission).Association("Roles").Replace(rolesModel)
-	if error != nil {
-		return nil, error
-	}
-	return rolesModel, nil
-}
-
-// Find All Role
-// @return []models.Role, error
-func FindAllRole() ([]models.Role, error) {
-	var roles []models.Role
-	res := conn.DB.Preload("Permissions").Find(&roles)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("RECORD NOT FOUND")
-		}
-		return nil, res.Error
-	}
-	return roles, nil
-}
-
-// Return all Permissions the Role.
-// @param string
-// @return []models.Permission
-func Permissions(roles ...string) ([]models.Permission, error) {
-	var permissions []models.Permission
-	var allPermission []models.Permission
-	for _, role := range roles {
-		_, err := FindRoleByName(role)
-		if err != nil {
-			return nil, errors.New("ROLE DOESN'T EXIST")
-		}
-		conn.DB.Model(&role).Association("Permissions").Find(&permissions)
-		allPermission = append(allPermission, permissions...)
-	}
-	return allPermission, nil
-}
-
-// Find Role By Name
-// @param string
-// @return models.Role, error
-func FindRoleByName(name string) (models.Role, error) {
-	var role models.Role
-	res := conn.DB.Where("name = ?", name).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return role, errors.New("RECORD NOT FOUND")
-		}
-		return role, res.Error
-	}
-	return role, nil
-}
-
-// Find Role By Id
-// @param uint
-// @return models.Role, error
-func FindRoleById(roleId uint) (models.Role, error) {
-	var role models.Role
-	res := conn.DB.Where("id = ?", roleId).Preload("Permissions").First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return role, errors.New("RECORD NOT FOUND")
-		}
-		return role, res.Error
-	}
-	return role, nil
-}
-
-// Find Or Create Role
-// @param models.Role
-// @return models.Role, error
-func FindOrCreateRole(role models.Role) (models.Role, error) {
-	var newRole models.Role
-	res := conn.DB.FirstOrCreate(&newRole, role)
-
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return newRole, errors.New("RECORD NOT FOUND")
-		}
-		if res.RowsAffected < 1 {
-			return newRole, errors.New("CANNOT BE INSERT OR CREATE DATA ")
-		}
-		return newRole, res.Error
-	}
-	return newRole, nil
-}
-
-// Get Name Roles
-// @param []models.Role
-// @return []string
-func GetNameRoles(roles []models.Role) []string {
-	var rolesName []string
-	for _, value := range roles {
-		rolesName = append(rolesName, value.Name)
-	}
-	return rolesName
-}
-
-// Revoke the given Permission by id for Role
-// @param uint, uint
-// @return bool, error
-func RemovePermissionByIdFromRole(roleId uint, permissionId uint) (bool, error) {
-	var role models.Role
-	var permission models.Permission
-
-	res := conn.DB.Where("id = ?", roleId).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-		return false, res.Error
-	}
-	res = conn.DB.Where("id = ?", permissionId).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-		return false, res.Error
-	}
-
-	error := conn.DB.Model(&role).Association("Permissions").Delete(&permission)
-	if error != nil {
-		return false, error
-	}
-	return true, nil
-}
-
-// Revoke the given Permission by name for Role
-// @param string, string
-// @return bool, error
-func RemovePermissionByNameFromRole(roleName string, permissionName string) (bool, error) {
-	var role models.Role
-	var permission models.Permission
-
-	res := conn.DB.Where("name = ?", roleName).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-		return false, res.Error
-	}
-	res = conn.DB.Where("name = ?", permissionName).First(&permission)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-		return false, res.Error
-	}
-
-	error := conn.DB.Model(&role).Association("Permissions").Delete(&permission)
-	if error != nil {
-		return false, error
-	}
-	return true, nil
-}
-
-// Return the number of Role permissions.
-// @param uint
-// @return int64, error
-func CountPermissionFromRole(roleId uint) (int64, error) {
-	var role models.Role
-
-	res := conn.DB.Where("id = ?", roleId).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return 0, errors.New("RECORD NOT FOUND")
-		}
-		return 0, res.Error
-	}
-	return conn.DB.Model(&role).Association("Permissions").Count(), nil
-}
-
-// Remove all current Permission for Role.
-// @param uint
-// @return bool, error
-func RemoveAllPermissionFromRole(roleId uint) (bool, error) {
-	var role models.Role
-
-	res := conn.DB.Where("id = ?", roleId).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-		return false, res.Error
-	}
-
-	error := conn.DB.Model(&role).Association("Permissions").Clear()
-	if error != nil {
-		return false, error
-	}
-
-	return true, nil
-}
-
-// Remove all current role Permission and set the given ones.
-// @param uint, string
-// @return []models.Permission, error
-func SyncPermissionsFromRole(roleId uint, permissions ...string) ([]models.Permission, error) {
-	var role models.Role
-	permissionModels := []models.Permission{}
-
-	res := conn.DB.Where("id = ?", roleId).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("RECORD NOT FOUND")
-		}
-		return nil, res.Error
-	}
-
-	for _, permissionName := range permissions {
-		permission, err := FindPermissionByName(permissionName)
-		if err != nil {
-			return permissionModels, errors.New("PERMISSION DOESN'T EXIST")
-		} else {
-			permissionModels = append(permissionModels, permission)
-		}
-	}
-
-	error := conn.DB.Model(&role).Association("Permissions").Replace(&permissionModels)
-	if error != nil {
-		return nil, error
-	}
-	return permissionModels, nil
-}
-
-// Assign the given Permissions to the Role.
-// @param uint, string
-// @return []models.Permission, error
-func AssignPermissionsFromRole(roleId uint, permissions ...string) ([]models.Permission, error) {
-	var role models.Role
-	permissionModels := []models.Permission{}
-
-	res := conn.DB.Where("id = ?", roleId).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("RECORD NOT FOUND")
-		}
-		return nil, res.Error
-	}
-
-	for _, permissionName := range permissions {
-		permission, err := FindPermissionByName(permissionName)
-		if err != nil {
-			return permissionModels, errors.New("PERMISSION DOESN'T EXIST")
-		} else {
-			permissionModels = append(permissionModels, permission)
-		}
-	}
-
-	error := conn.DB.Model(&role).Association("Permissions").Append(&permissionModels)
-	if error != nil {
-		return nil, error
-	}
-	return permissionModels, nil
-}
-
-// Determine if the Role may perform the given permission.
-// @param uint, string
-// @return models.Permission, error
-func HasPermissionTo(roleId uint, permissionName string) (models.Permission, error) {
-	var role models.Role
-
-	res := conn.DB.Where("id = ?", roleId).First(&role)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return models.Permission{}, errors.New("RECORD NOT FOUND")
-		}
-		return models.Permission{}, res.Error
-	}
-	var permission models.Permission
-
-	permissionId, error := FindPermissionByName(permissionName)
-	if error != nil {
-		return permission, error
-	}
-
-	error = conn.DB.Model(&role).Where("permission_id = ?", permissionId.ID).Association("Permissions").Find(&permission)
-	if error != nil {
-		return permission, error
-	}
-	return permission, nil
-}
-
-// Return all the Roles the user.
-// @param uint
-// @return []models.Role, error
-func GetRole(userID uint) ([]models.Role, error) {
-	var userRoles []models.UserRoles
-	res := conn.DB.Where("user_id = ?", userID).Find(&userRoles)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return []models.Role{}, errors.New("RECORD NOT FOUND")
-		}
-		return []models.Role{}, res.Error
-	}
-
-	var roles []models.Role
-	for _, r := range userRoles {
-		var role models.Role
-		res := conn.DB.Where("id = ?", r.RoleID).Find(&role)
-		if res.Error != nil {
-			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-				return []models.Role{}, errors.New("ROLE ID NOT FOUND")
-			}
-			return []models.Role{}, res.Error
-		}
-		if res.Error == nil {
-			roles = append(roles, role)
-		}
-	}
-	return roles, nil
-}
-
-// Return all the Roles Name the user.
-// @param uint
-// @return []string, error
-func GetRoleNames(userID uint) ([]string, error) {
-	var userRoles []models.UserRoles
-	res := conn.DB.Where("user_id = ?", userID).Find(&userRoles)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("RECORD NOT FOUND")
-		}
-		return nil, res.Error
-	}
-
-	var roles []models.Role
-	for _, r := range userRoles {
-		var role models.Role
-		res := conn.DB.Where("id = ?", r.RoleID).Find(&role)
-		if res.Error != nil {
-			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-				return nil, errors.New("ROLE ID NOT FOUND")
-			}
-			return nil, res.Error
-		}
-		if res.Error == nil {
-			roles = append(roles, role)
-		}
-	}
-	return GetNameRoles(roles), nil
-}
-
-// Return all the permissions the user.
-// @param uint
-// @return []models.Permission, error
-func GetAllPermissions(userID uint) ([]models.Permission, error) {
-	var userRoles []models.UserRoles
-	res := conn.DB.Where("user_id = ?", userID).Find(&userRoles)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("RECORD NOT FOUND")
-		}
-		return nil, res.Error
-	}
-
-	var roles []string
-	for _, r := range userRoles {
-		var role models.Role
-		res := conn.DB.Where("id = ?", r.RoleID).Find(&role)
-		if res.Error != nil {
-			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-				return nil, errors.New("ROLE ID NOT FOUND")
-			}
-			return nil, res.Error
-		}
-		if res.Error == nil {
-			roles = append(roles, role.Name)
-		}
-	}
-	permissions, error := Permissions(roles...)
-	if error != nil {
-		return nil, error
-	}
-	return permissions, nil
-}
-
-// Assign the given roles to the User.
-// @param uint, string
-// @return bool, error
-func AssignRoles(userID uint, Roles ...string) (bool, error) {
-	var userRole models.UserRoles
-
-	for _, roleName := range Roles {
-		role, err := FindRoleByName(roleName)
-		if err != nil {
-			return false, errors.New("ROLE DOESN'T EXIST")
-		}
-		conn.DB.FirstOrCreate(&userRole, models.UserRoles{
-			UserID: userID,
-			RoleID: role.ID,
-		})
-	}
-
-	return true, nil
-}
-
-// Revoke the given role by id for user
-// @param uint, uint
-// @return bool, error
-func RemoveRoleByIdFromUser(userID uint, roleId uint) (bool, error) {
-	res := conn.DB.Where("user_id = ?", userID).Where("role_id = ?", roleId).Delete(&models.UserRoles{})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE DELETED BECAUSE IT DOESN'T EXIST")
-	}
-	return true, nil
-}
-
-// Revoke the given role by name for user
-// @param uint, string
-// @return bool, error
-func RemoveRoleByNameFromUser(userID uint, roleName string) (bool, error) {
-	role, error := FindRoleByName(roleName)
-	if error != nil {
-		return false, error
-	}
-	res := conn.DB.Where("user_id = ?", userID).Where("role_id = ?", role.ID).Delete(&models.UserRoles{})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE DELETED BECAUSE IT DOESN'T EXIST")
-	}
-	return true, nil
-}
-
-// Remove all current roles for user.
-// @param uint
-// @return bool, error
-func RemoveAllRoleFromUser(userID uint) (bool, error) {
-	res := conn.DB.Where("user_id = ?", userID).Delete(&models.UserRoles{})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE DELETED BECAUSE IT DOESN'T EXIST")
-	}
-	return true, nil
-}
-
-// Remove all current user roles and set the given ones.
-// @param uint, string
-// @return bool, error
-func SyncRolesFromUser(userID uint, Roles ...string) (bool, error) {
-	res := conn.DB.Where("user_id = ?", userID).Delete(&models.UserRoles{})
-	if res.Error != nil {
-		return false, res.Error
-	} else if res.RowsAffected < 1 {
-		return false, errors.New("CANNOT BE DELETED BECAUSE IT DOESN'T EXIST")
-	}
-
-	for _, roleName := range Roles {
-		role, err := FindRoleByName(roleName)
-		if err != nil {
-			return false, errors.New("ROLE DOESN'T EXIST")
-		}
-		conn.DB.Create(&models.UserRoles{
-			UserID: userID,
-			RoleID: role.ID,
-		})
-	}
-	return true, nil
-}
-
-// Determine if the user has  of the given role id.
-// @param uint, uint
-// @return bool, error
-func HasRole(userID uint, roleId uint) (bool, error) {
-	_, error := FindRoleById(roleId)
-	if error != nil {
-		return false, error
-	}
-
-	var userRole models.UserRoles
-	res := conn.DB.Where("user_id = ?", userID).Where("role_id = ?", roleId).First(&userRole)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return false, errors.New("RECORD NOT FOUND")
-		}
-		return false, res.Error
-	}
-	return true, nil
-}
-
-// Determine if the user has of the given roles name.
-// @param uint, uint
-// @return bool, error
-func HasAnyRole(userID uint, rolesName ...string) (bool, error) {
-	roles, error := GetRole(userID)
-	if error != nil {
-		return false, error
-	}
-	for _, role := range roles {
-		for _, name := range rolesName {
-			if role.Name == name {
-				return true, nil
-			}
-		}
-	}
-	return false, nil
-}
-
-// Determine if the user has all of the given roles name.
-// @param uint, uint
-// @return bool, error
-func HasAllRole(userID uint, rolesName ...string) (bool, error) {
-	roles, error := GetRole(userID)
-	if error != nil {
-		return false, error
-	}
-
-	for index, role := range roles {
-		if role.Name != rolesName[index] {
-			return false, nil
-		}
-	}
-
-	return true, nil
-}
-
-// Determine if the user has all of the given permissions name.
-// @param uint, uint
-// @return bool, error
-func HasAllPermission(userID uint, permissionsName ...string) (bool, error) {
-	permissions, error := GetAllPermissions(userID)
-	if error != nil {
-		return false, error
-	}
-
-	for index, permission := range permissions {
-		if permission.Name != permissionsName[index] {
-			return false, nil
-		}
-	}
-
-	return true, nil
-}
-
-// Determine if the User has of the given permissions name.
-// @param uint, string
-// @return bool, error
-func HasAnyPermissions(userID uint, permissionsName ...string) (bool, error) {
-	permissions, error := GetAllPermissions(userID)
-	if error != nil {
-		return false, error
-	}
-	for _, permission := range permissions {
-		for _, name := range permissionsName {
-			if permission.Name == name {
-				return true, nil
-			}
-		}
-	}
-	return false, nil
-}
+package grole
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mousav1/grole/migrate"
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+type Options struct {
+	DB *gorm.DB
+	// PermissionSeparator separates the parts of permission names, "." by default.
+	PermissionSeparator string
+	// CacheTTL enables the in-process cache of the effective roles and
+	// permissions of users when positive, entries expire after it.
+	CacheTTL time.Duration
+	// CacheSize bounds the number of entries of the in-process cache,
+	// DefaultCacheSize by default.
+	CacheSize int
+	// Cache replaces the in-process cache, e.g. by a cache shared by every
+	// instance, its entries expire after CacheTTL or DefaultCacheTTL.
+	Cache Cache
+	// Broadcaster publishes cache invalidations to the other instances, run
+	// ListenInvalidations to receive theirs.
+	Broadcaster Broadcaster
+	// OnError is called with the errors of background work, like a cache or
+	// broadcaster failure, which don't fail the operation itself.
+	OnError func(error)
+	// Outbox writes every change event to the outbox table in the transaction
+	// making it, run a Relay to deliver them.
+	Outbox bool
+}
+
+// Enforcer manages the roles and permissions stored in one database.
+// Every Enforcer is independent, so several of them can be used side by side.
+type Enforcer struct {
+	db      *gorm.DB
+	ctx     context.Context
+	scope   string
+	actor   string
+	opts    Options
+	txState *txState
+
+	cache           Cache
+	cacheGeneration *uint64
+	instanceID      string
+	hooks           *hooks
+}
+
+// set database connection and make it the default enforcer
+// @param Options
+// @return *Enforcer
+func New(opt Options) *Enforcer {
+	defaultEnforcer = NewEnforcer(opt)
+	return defaultEnforcer
+}
+
+// create a new enforcer without changing the default one
+// @param Options
+// @return *Enforcer
+func NewEnforcer(opt Options) *Enforcer {
+	migrate.MigrateTables(opt.DB)
+	e := &Enforcer{
+		db:              opt.DB,
+		ctx:             context.Background(),
+		opts:            opt,
+		cache:           opt.Cache,
+		cacheGeneration: new(uint64),
+		instanceID:      newInstanceID(),
+		hooks:           &hooks{},
+	}
+	if e.cache == nil && opt.CacheTTL > 0 {
+		e.cache = NewMemoryCache(opt.CacheSize)
+	}
+	return e
+}
+
+// Return the database connection of the enforcer.
+// @return *gorm.DB
+func (e *Enforcer) DB() *gorm.DB {
+	return e.db
+}
+
+// Return a copy of the enforcer whose queries all run with the given context,
+// so deadlines and cancellations reach the database. The scope carried by the
+// context, if any, becomes the scope of the enforcer, and so does its actor.
+// @param context.Context
+// @return *Enforcer
+func (e *Enforcer) WithContext(ctx context.Context) *Enforcer {
+	clone := *e
+	clone.ctx = ctx
+	clone.db = e.db.WithContext(ctx)
+	if scope, ok := ScopeFromContext(ctx); ok {
+		clone.scope = scope
+	}
+	if actor, ok := ActorFromContext(ctx); ok {
+		clone.actor = actor
+	}
+	return &clone
+}
+
+// Return the context used by the enforcer.
+// @return context.Context
+func (e *Enforcer) Context() context.Context {
+	return e.ctx
+}
+
+// delete the given role
+// @param uint
+// @return bool, error
+func (e *Enforcer) DeleteRole(roleId uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var count int64
+		res := tx.db.Model(&models.UserRoles{}).Where("role_id = ?", roleId).Count(&count)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		if count > 0 {
+			return newError(EntityRole, roleId, ErrRoleAssigned)
+		}
+
+		var role models.Role
+		res = tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+
+		res = tx.db.Where("id = ?", roleId).Delete(&models.Role{})
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityRole, roleId, ErrRoleNotFound)
+		}
+
+		res = tx.db.Where("role_id = ? OR parent_id = ?", roleId, roleId).Delete(&models.RoleHierarchy{})
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		res = tx.db.Where("role_id = ?", roleId).Delete(&models.ResourcePermission{})
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		tx.changed(roleDeleted(role))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// update the given role
+// @param uint
+// @return bool, error
+func (e *Enforcer) UpdateRole(roleId uint, newRole models.Role) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var before, role models.Role
+		res := tx.db.Where("id = ?", roleId).First(&before)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+
+		res = tx.db.Where("id = ?", roleId).Updates(models.Role{Name: newRole.Name, Description: newRole.Description})
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityRole, roleId, ErrRoleNotFound)
+		}
+
+		res = tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		tx.changed(roleUpdated(before, role))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// delete the given permission
+// @param uint
+// @return bool, error
+func (e *Enforcer) DeletePermission(permissionId uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var permission models.Permission
+
+		res := tx.db.Where("id = ?", permissionId).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+
+		roleCount := tx.db.Model(&permission).Association("Roles").Count()
+		if roleCount > 0 {
+			return newError(EntityPermission, permissionId, ErrPermissionAssigned)
+		}
+
+		var userCount int64
+		res = tx.db.Model(&models.UserPermissions{}).Where("permission_id = ?", permissionId).Count(&userCount)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+		if userCount > 0 {
+			return newError(EntityPermission, permissionId, ErrPermissionAssigned)
+		}
+
+		var resourceCount int64
+		res = tx.db.Model(&models.ResourcePermission{}).Where("permission_id = ?", permissionId).Count(&resourceCount)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+		if resourceCount > 0 {
+			return newError(EntityPermission, permissionId, ErrPermissionAssigned)
+		}
+
+		res = tx.db.Where("id = ?", permissionId).Delete(&models.Permission{})
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityPermission, permissionId, ErrPermissionNotFound)
+		}
+		tx.changed(permissionDeleted(permission))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// update the given permission
+// @param uint
+// @return bool, error
+func (e *Enforcer) UpdatePermission(permissionId uint, newPermission models.Permission) (bool, error) {
+	if newPermission.Name != "" {
+		if err := ValidatePermissionName(newPermission.Name, e.separator()); err != nil {
+			return false, err
+		}
+	}
+	err := e.transaction(func(tx *Enforcer) error {
+		var before, permission models.Permission
+		res := tx.db.Where("id = ?", permissionId).First(&before)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+
+		res = tx.db.Where("id = ?", permissionId).Updates(models.Permission{Name: newPermission.Name, Description: newPermission.Description})
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityPermission, permissionId, ErrPermissionNotFound)
+		}
+
+		res = tx.db.Where("id = ?", permissionId).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+		tx.changed(permissionUpdated(before, permission))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Show All Permission With Role
+// @return []models.Permission, error
+func (e *Enforcer) FindAllPermission() ([]models.Permission, error) {
+	var permissions []models.Permission
+	res := e.db.Find(&permissions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if err := e.preloadRoles(permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// Find roles of each permission
+// @param string
+// @return []models.Role
+func (e *Enforcer) Roles(permissions ...string) ([]models.Role, error) {
+	permissionModels, err := e.findPermissionsByName(permissions)
+	if err != nil {
+		return nil, err
+	}
+	var permissionIds []uint
+	for _, permission := range permissionModels {
+		permissionIds = append(permissionIds, permission.ID)
+	}
+
+	var roles []models.Role
+	permissionRoles := active(e.db.Table("permission_role").Select("role_id").Where("permission_id IN ?", permissionIds), "permission_role")
+	res := e.db.Where("id IN (?)", permissionRoles).Order("id").Find(&roles)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return roles, nil
+}
+
+// find Permission By Name and Show each with Role
+// @param string
+// @return models.Permission, error
+func (e *Enforcer) FindPermissionByName(name string) (models.Permission, error) {
+	var permission models.Permission
+	res := e.db.Where("name = ?", name).First(&permission)
+	if res.Error != nil {
+		return permission, wrapError(EntityPermission, name, res.Error)
+	}
+	permissions := []models.Permission{permission}
+	if err := e.preloadRoles(permissions); err != nil {
+		return permission, wrapError(EntityPermission, name, err)
+	}
+	return permissions[0], nil
+}
+
+// find Permission By Id and Show each with Role
+// @param uint
+// @return models.Permission, error
+func (e *Enforcer) FindPermissionById(id uint) (models.Permission, error) {
+	var permission models.Permission
+	res := e.db.Where("id = ?", id).First(&permission)
+	if res.Error != nil {
+		return permission, wrapError(EntityPermission, id, res.Error)
+	}
+	permissions := []models.Permission{permission}
+	if err := e.preloadRoles(permissions); err != nil {
+		return permission, wrapError(EntityPermission, id, err)
+	}
+	return permissions[0], nil
+}
+
+// find Permission or Create Permission If not found, a malformed name returns ErrInvalidPermissionName.
+// @param models.Permission
+// @return models.Permission, error
+func (e *Enforcer) FindOrCreatePermission(permission models.Permission) (models.Permission, error) {
+	var newPermission models.Permission
+	if err := ValidatePermissionName(permission.Name, e.separator()); err != nil {
+		return newPermission, err
+	}
+	err := e.transaction(func(tx *Enforcer) error {
+		res := tx.db.Where(permission).Limit(1).Find(&newPermission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permission.Name, res.Error)
+		} else if res.RowsAffected > 0 {
+			return nil
+		}
+
+		newPermission = permission
+		res = tx.db.Create(&newPermission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permission.Name, res.Error)
+		}
+		tx.changed(permissionCreated(newPermission))
+		return nil
+	})
+	if err != nil {
+		return models.Permission{}, err
+	}
+	return newPermission, nil
+}
+
+// Revoke the given role by id for permission
+// @param uint, uint
+// @return bool, error
+func (e *Enforcer) RemoveRoleByIdFromPermission(permissionId uint, roleId uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var role models.Role
+		var permission models.Permission
+
+		res := tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		res = tx.db.Where("id = ?", permissionId).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+
+		granted, err := tx.rolePermissionGranted(role.ID, permission.ID)
+		if err != nil {
+			return wrapError(EntityPermission, permissionId, err)
+		} else if !granted {
+			return nil
+		}
+		err = tx.db.Model(&permission).Association("Roles").Delete(&role)
+		if err != nil {
+			return wrapError(EntityPermission, permissionId, err)
+		}
+		tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Revoke the given role by name for permission
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) RemoveRoleByNameFromPermission(permissionId uint, roleName string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var permission models.Permission
+
+		role, err := tx.FindRoleByName(roleName)
+		if err != nil {
+			return err
+		}
+		res := tx.db.Where("id = ?", permissionId).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+
+		granted, err := tx.rolePermissionGranted(role.ID, permission.ID)
+		if err != nil {
+			return wrapError(EntityPermission, permissionId, err)
+		} else if !granted {
+			return nil
+		}
+		err = tx.db.Model(&permission).Association("Roles").Delete(&role)
+		if err != nil {
+			return wrapError(EntityPermission, permissionId, err)
+		}
+		tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Revoke the given role for permission
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) RemoveRoleFromPermission(permissionId uint, roleName string) (bool, error) {
+	return e.RemoveRoleByNameFromPermission(permissionId, roleName)
+}
+
+// Return the number of permissions Role.
+// @param uint
+// @return int64, error
+func (e *Enforcer) CountRoleFromPermission(permissionId uint) (int64, error) {
+	var permission models.Permission
+
+	res := e.db.Where("id = ?", permissionId).First(&permission)
+	if res.Error != nil {
+		return 0, wrapError(EntityPermission, permissionId, res.Error)
+	}
+	count, err := e.countActiveGrants("permission_id", permissionId)
+	if err != nil {
+		return 0, wrapError(EntityPermission, permissionId, err)
+	}
+	return count, nil
+}
+
+// Remove all current Role for Permission.
+// @param uint
+// @return bool, error
+func (e *Enforcer) RemoveAllRoleFromPermission(permissionId uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var permission models.Permission
+
+		res := tx.db.Where("id = ?", permissionId).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+
+		var roles []models.Role
+		err := tx.db.Model(&permission).Association("Roles").Find(&roles)
+		if err != nil {
+			return wrapError(EntityPermission, permissionId, err)
+		}
+		err = tx.db.Model(&permission).Association("Roles").Clear()
+		if err != nil {
+			return wrapError(EntityPermission, permissionId, err)
+		}
+		for _, role := range roles {
+			tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove all current Permission role and set the given ones.
+// @param uint, string
+// @return []models.Role, error
+func (e *Enforcer) SyncRolesFromPermission(permissionId uint, roles ...string) ([]models.Role, error) {
+	var rolesModel []models.Role
+	err := e.transaction(func(tx *Enforcer) error {
+		var permission models.Permission
+
+		res := tx.db.Where("id = ?", permissionId).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+
+		var err error
+		rolesModel, err = tx.findRolesByName(roles)
+		if err != nil {
+			return err
+		}
+
+		// only the active grants are revoked, the permission is granted to the
+		// given roles without time limit, whatever the validity of their grant.
+		var current []models.Role
+		grants := active(tx.db.Table("permission_role").Select("role_id").Where("permission_id = ?", permissionId), "permission_role")
+		res = tx.db.Where("id IN (?)", grants).Order("id").Find(&current)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+		unlisted := tx.db.Where("permission_id = ?", permissionId)
+		if len(rolesModel) > 0 {
+			var roleIds []uint
+			for _, role := range rolesModel {
+				roleIds = append(roleIds, role.ID)
+			}
+			unlisted = unlisted.Where("role_id NOT IN ?", roleIds)
+		}
+		res = unlisted.Delete(&models.PermissionRole{})
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+		for _, role := range difference(current, rolesModel, roleID) {
+			tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		}
+		for _, role := range rolesModel {
+			if err := tx.grantPermissions(role, []models.Permission{permission}, nil, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rolesModel, nil
+}
+
+// Find All Role
+// @return []models.Role, error
+func (e *Enforcer) FindAllRole() ([]models.Role, error) {
+	var roles []models.Role
+	res := e.db.Where("scope IN ?", e.scopes()).Find(&roles)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if err := e.preloadPermissions(roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Return all Permissions the Role.
+// @param string
+// @return []models.Permission
+func (e *Enforcer) Permissions(roles ...string) ([]models.Permission, error) {
+	return cachedUntil(e, rolesCacheKey(roles, "permissions"), e.nextGrantChange, func() ([]models.Permission, error) {
+		return e.permissions(roles)
+	})
+}
+
+// permissions load the permissions of the roles from the database.
+func (e *Enforcer) permissions(roles []string) ([]models.Permission, error) {
+	roleModels, err := e.findRolesByName(roles)
+	if err != nil {
+		return nil, err
+	}
+	var roleIds []uint
+	for _, role := range roleModels {
+		roleIds = append(roleIds, role.ID)
+	}
+
+	var permissions []models.Permission
+	rolePermissions := active(e.db.Table("permission_role").Select("permission_id").Where("role_id IN ?", roleIds), "permission_role")
+	res := e.db.Where("id IN (?)", rolePermissions).Order("id").Find(&permissions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return permissions, nil
+}
+
+// Find Role By Name, a role of the enforcer scope is preferred over a global one.
+// @param string
+// @return models.Role, error
+func (e *Enforcer) FindRoleByName(name string) (models.Role, error) {
+	var role models.Role
+	res := e.db.Where("name = ?", name).Where("scope IN ?", e.scopes()).Order("scope DESC").First(&role)
+	if res.Error != nil {
+		return role, wrapError(EntityRole, name, res.Error)
+	}
+	return role, nil
+}
+
+// Find Role By Id
+// @param uint
+// @return models.Role, error
+func (e *Enforcer) FindRoleById(roleId uint) (models.Role, error) {
+	var role models.Role
+	res := e.db.Where("id = ?", roleId).First(&role)
+	if res.Error != nil {
+		return role, wrapError(EntityRole, roleId, res.Error)
+	}
+	roles := []models.Role{role}
+	if err := e.preloadPermissions(roles); err != nil {
+		return role, wrapError(EntityRole, roleId, err)
+	}
+	return roles[0], nil
+}
+
+// Find Or Create Role, a role without a scope is created in the enforcer scope.
+// @param models.Role
+// @return models.Role, error
+func (e *Enforcer) FindOrCreateRole(role models.Role) (models.Role, error) {
+	var newRole models.Role
+	if role.Scope == "" {
+		role.Scope = e.scope
+	}
+	err := e.transaction(func(tx *Enforcer) error {
+		res := tx.db.Where("scope = ?", role.Scope).Where(role).Limit(1).Find(&newRole)
+		if res.Error != nil {
+			return wrapError(EntityRole, role.Name, res.Error)
+		} else if res.RowsAffected > 0 {
+			return nil
+		}
+
+		newRole = role
+		res = tx.db.Create(&newRole)
+		if res.Error != nil {
+			return wrapError(EntityRole, role.Name, res.Error)
+		}
+		tx.changed(roleCreated(newRole))
+		return nil
+	})
+	if err != nil {
+		return models.Role{}, err
+	}
+	return newRole, nil
+}
+
+// Get Name Roles
+// @param []models.Role
+// @return []string
+func GetNameRoles(roles []models.Role) []string {
+	var rolesName []string
+	for _, value := range roles {
+		rolesName = append(rolesName, value.Name)
+	}
+	return rolesName
+}
+
+// Revoke the given Permission by id for Role
+// @param uint, uint
+// @return bool, error
+func (e *Enforcer) RemovePermissionByIdFromRole(roleId uint, permissionId uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var role models.Role
+		var permission models.Permission
+
+		res := tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		res = tx.db.Where("id = ?", permissionId).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionId, res.Error)
+		}
+
+		granted, err := tx.rolePermissionGranted(role.ID, permission.ID)
+		if err != nil {
+			return wrapError(EntityRole, roleId, err)
+		} else if !granted {
+			return nil
+		}
+		err = tx.db.Model(&role).Association("Permissions").Delete(&permission)
+		if err != nil {
+			return wrapError(EntityRole, roleId, err)
+		}
+		tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Revoke the given Permission by name for Role
+// @param string, string
+// @return bool, error
+func (e *Enforcer) RemovePermissionByNameFromRole(roleName string, permissionName string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var permission models.Permission
+
+		role, err := tx.FindRoleByName(roleName)
+		if err != nil {
+			return err
+		}
+		res := tx.db.Where("name = ?", permissionName).First(&permission)
+		if res.Error != nil {
+			return wrapError(EntityPermission, permissionName, res.Error)
+		}
+
+		granted, err := tx.rolePermissionGranted(role.ID, permission.ID)
+		if err != nil {
+			return wrapError(EntityRole, roleName, err)
+		} else if !granted {
+			return nil
+		}
+		err = tx.db.Model(&role).Association("Permissions").Delete(&permission)
+		if err != nil {
+			return wrapError(EntityRole, roleName, err)
+		}
+		tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Return the number of Role permissions.
+// @param uint
+// @return int64, error
+func (e *Enforcer) CountPermissionFromRole(roleId uint) (int64, error) {
+	var role models.Role
+
+	res := e.db.Where("id = ?", roleId).First(&role)
+	if res.Error != nil {
+		return 0, wrapError(EntityRole, roleId, res.Error)
+	}
+	count, err := e.countActiveGrants("role_id", roleId)
+	if err != nil {
+		return 0, wrapError(EntityRole, roleId, err)
+	}
+	return count, nil
+}
+
+// Remove all current Permission for Role.
+// @param uint
+// @return bool, error
+func (e *Enforcer) RemoveAllPermissionFromRole(roleId uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var role models.Role
+
+		res := tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+
+		var permissions []models.Permission
+		err := tx.db.Model(&role).Association("Permissions").Find(&permissions)
+		if err != nil {
+			return wrapError(EntityRole, roleId, err)
+		}
+		err = tx.db.Model(&role).Association("Permissions").Clear()
+		if err != nil {
+			return wrapError(EntityRole, roleId, err)
+		}
+		for _, permission := range permissions {
+			tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove all current role Permission and set the given ones.
+// @param uint, string
+// @return []models.Permission, error
+func (e *Enforcer) SyncPermissionsFromRole(roleId uint, permissions ...string) ([]models.Permission, error) {
+	var permissionModels []models.Permission
+	err := e.transaction(func(tx *Enforcer) error {
+		var role models.Role
+
+		res := tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+
+		var err error
+		permissionModels, err = tx.findPermissionsByName(permissions)
+		if err != nil {
+			return err
+		}
+
+		// only the active grants are revoked, the given permissions are granted
+		// without time limit, whatever the validity of their current grant.
+		var current []models.Permission
+		grants := active(tx.db.Table("permission_role").Select("permission_id").Where("role_id = ?", roleId), "permission_role")
+		res = tx.db.Where("id IN (?)", grants).Order("id").Find(&current)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		unlisted := tx.db.Where("role_id = ?", roleId)
+		if len(permissionModels) > 0 {
+			var permissionIds []uint
+			for _, permission := range permissionModels {
+				permissionIds = append(permissionIds, permission.ID)
+			}
+			unlisted = unlisted.Where("permission_id NOT IN ?", permissionIds)
+		}
+		res = unlisted.Delete(&models.PermissionRole{})
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		for _, permission := range difference(current, permissionModels, permissionID) {
+			tx.changed(rolePermissionChange(ActionPermissionRevoked, role, permission))
+		}
+		return tx.grantPermissions(role, permissionModels, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return permissionModels, nil
+}
+
+// Assign the given Permissions to the Role, without time limit.
+// @param uint, string
+// @return []models.Permission, error
+func (e *Enforcer) AssignPermissionsFromRole(roleId uint, permissions ...string) ([]models.Permission, error) {
+	return e.assignPermissionsFromRole(roleId, time.Time{}, time.Time{}, permissions)
+}
+
+// Determine if the Role may perform the given permission.
+// @param uint, string
+// @return models.Permission, error
+func (e *Enforcer) HasPermissionTo(roleId uint, permissionName string) (models.Permission, error) {
+	var role models.Role
+
+	res := e.db.Where("id = ?", roleId).First(&role)
+	if res.Error != nil {
+		return models.Permission{}, wrapError(EntityRole, roleId, res.Error)
+	}
+	var permission models.Permission
+
+	permissionId, err := e.FindPermissionByName(permissionName)
+	if err != nil {
+		return permission, err
+	}
+
+	err = active(e.db.Model(&role).Where("permission_id = ?", permissionId.ID), "permission_role").Association("Permissions").Find(&permission)
+	if err != nil {
+		return permission, wrapError(EntityRole, roleId, err)
+	}
+	return permission, nil
+}
+
+// Return all the Roles the user.
+// @param uint
+// @return []models.Role, error
+func (e *Enforcer) GetRole(userID uint) ([]models.Role, error) {
+	roles := []models.Role{}
+	res := e.db.Where("id IN (?)", e.userRoleIDs(userID)).Order("id").Find(&roles)
+	if res.Error != nil {
+		return []models.Role{}, wrapError(EntityUser, userID, res.Error)
+	}
+	return roles, nil
+}
+
+// Return all the Roles Name the user.
+// @param uint
+// @return []string, error
+func (e *Enforcer) GetRoleNames(userID uint) ([]string, error) {
+	roles, err := e.GetRole(userID)
+	if err != nil {
+		return nil, err
+	}
+	return GetNameRoles(roles), nil
+}
+
+// Return all the permissions the user, the direct ones and the ones of its roles
+// and the roles they inherit from.
+// @param uint
+// @return []models.Permission, error
+func (e *Enforcer) GetAllPermissions(userID uint) ([]models.Permission, error) {
+	return cachedUntil(e, userCacheKey(userID, "permissions"), e.userCacheExpiry(userID), func() ([]models.Permission, error) {
+		return e.getAllPermissions(userID)
+	})
+}
+
+// getAllPermissions load the effective permissions of the user from the database.
+func (e *Enforcer) getAllPermissions(userID uint) ([]models.Permission, error) {
+	graph, err := e.roleGraph()
+	if err != nil {
+		return nil, wrapError(EntityUser, userID, err)
+	}
+
+	// without inheritance the roles of the user are joined directly, otherwise
+	// the inherited roles are resolved first.
+	// the assignments and the grants both have to be active.
+	rolePermissions := e.db.Table("permission_role").Select("permission_role.permission_id").
+		Joins("JOIN user_roles ON user_roles.role_id = permission_role.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Where("user_roles.scope IN ?", e.scopes())
+	rolePermissions = active(active(rolePermissions, "user_roles"), "permission_role")
+	if len(graph) > 0 {
+		var roleIds []uint
+		res := e.userRoleIDs(userID).Scan(&roleIds)
+		if res.Error != nil {
+			return nil, wrapError(EntityUser, userID, res.Error)
+		}
+		rolePermissions = active(e.db.Table("permission_role").Select("permission_id").
+			Where("role_id IN ?", graph.withAncestors(roleIds)), "permission_role")
+	}
+	directPermissions := e.db.Table("user_permissions").Select("permission_id").Where("user_id = ?", userID)
+
+	var permissions []models.Permission
+	res := e.db.Where("id IN (?)", rolePermissions).Or("id IN (?)", directPermissions).Order("id").Find(&permissions)
+	if res.Error != nil {
+		return nil, wrapError(EntityUser, userID, res.Error)
+	}
+	return permissions, nil
+}
+
+// Assign the given roles to the User, without time limit.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) AssignRoles(userID uint, Roles ...string) (bool, error) {
+	return e.assignRoles(userID, time.Time{}, time.Time{}, Roles)
+}
+
+// Revoke the given role by id for user
+// @param uint, uint
+// @return bool, error
+func (e *Enforcer) RemoveRoleByIdFromUser(userID uint, roleId uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		res := tx.db.Where("user_id = ?", userID).Where("role_id = ?", roleId).Where("scope = ?", tx.scope).Delete(&models.UserRoles{})
+		if res.Error != nil {
+			return wrapError(EntityUser, userID, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityRole, roleId, ErrRoleNotAssigned)
+		}
+		role := models.Role{ID: roleId}
+		res = tx.db.Where("id = ?", roleId).Limit(1).Find(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+		tx.changed(userRoleChange(ActionRoleRevoked, userID, role))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Revoke the given role by name for user
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) RemoveRoleByNameFromUser(userID uint, roleName string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		role, err := tx.FindRoleByName(roleName)
+		if err != nil {
+			return err
+		}
+		res := tx.db.Where("user_id = ?", userID).Where("role_id = ?", role.ID).Where("scope = ?", tx.scope).Delete(&models.UserRoles{})
+		if res.Error != nil {
+			return wrapError(EntityUser, userID, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityRole, roleName, ErrRoleNotAssigned)
+		}
+		tx.changed(userRoleChange(ActionRoleRevoked, userID, role))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove all current roles for user.
+// @param uint
+// @return bool, error
+func (e *Enforcer) RemoveAllRoleFromUser(userID uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		roles, err := tx.scopeRoles(userID)
+		if err != nil {
+			return wrapError(EntityUser, userID, err)
+		}
+		res := tx.db.Where("user_id = ?", userID).Where("scope = ?", tx.scope).Delete(&models.UserRoles{})
+		if res.Error != nil {
+			return wrapError(EntityUser, userID, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityUser, userID, ErrRoleNotAssigned)
+		}
+		for _, role := range roles {
+			tx.changed(userRoleChange(ActionRoleRevoked, userID, role))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove all current user roles and set the given ones.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) SyncRolesFromUser(userID uint, Roles ...string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		roles, err := tx.findRolesByName(Roles)
+		if err != nil {
+			return err
+		}
+		current, err := tx.scopeRoles(userID)
+		if err != nil {
+			return wrapError(EntityUser, userID, err)
+		}
+
+		res := tx.db.Where("user_id = ?", userID).Where("scope = ?", tx.scope).Delete(&models.UserRoles{})
+		if res.Error != nil {
+			return wrapError(EntityUser, userID, res.Error)
+		}
+
+		for _, role := range roles {
+			res := tx.db.Create(&models.UserRoles{
+				UserID: userID,
+				RoleID: role.ID,
+				Scope:  tx.scope,
+			})
+			if res.Error != nil {
+				return wrapError(EntityUser, userID, res.Error)
+			}
+		}
+		for _, role := range difference(current, roles, roleID) {
+			tx.changed(userRoleChange(ActionRoleRevoked, userID, role))
+		}
+		for _, role := range difference(roles, current, roleID) {
+			tx.changed(userRoleChange(ActionRoleAssigned, userID, role))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Determine if the user has  of the given role id.
+// @param uint, uint
+// @return bool, error
+func (e *Enforcer) HasRole(userID uint, roleId uint) (bool, error) {
+	_, err := e.FindRoleById(roleId)
+	if err != nil {
+		return false, err
+	}
+
+	var userRole models.UserRoles
+	userRoles := e.db.Where("user_id = ?", userID).Where("role_id = ?", roleId).Where("scope IN ?", e.scopes())
+	res := active(userRoles, "user_roles").First(&userRole)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return false, newError(EntityRole, roleId, ErrRoleNotAssigned)
+		}
+		return false, wrapError(EntityUser, userID, res.Error)
+	}
+	return true, nil
+}
+
+// Determine if the user has of the given roles name, inherited roles included.
+// @param uint, uint
+// @return bool, error
+func (e *Enforcer) HasAnyRole(userID uint, rolesName ...string) (bool, error) {
+	roles, err := e.GetAllRoles(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		for _, name := range rolesName {
+			if role.Name == name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Determine if the user has all of the given roles name, inherited roles included.
+// @param uint, uint
+// @return bool, error
+func (e *Enforcer) HasAllRole(userID uint, rolesName ...string) (bool, error) {
+	result, err := e.CheckRoles(userID, rolesName...)
+	if err != nil {
+		return false, err
+	}
+	return result.Allowed(), nil
+}
+
+// Determine if the user has all of the given permissions name, granted wildcards included.
+// @param uint, uint
+// @return bool, error
+func (e *Enforcer) HasAllPermission(userID uint, permissionsName ...string) (bool, error) {
+	result, err := e.CheckPermissions(userID, permissionsName...)
+	if err != nil {
+		return false, err
+	}
+	return result.Allowed(), nil
+}
+
+// Determine if the User has of the given permissions name, granted wildcards included.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) HasAnyPermissions(userID uint, permissionsName ...string) (bool, error) {
+	permissions, err := e.GetAllPermissions(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range permissionsName {
+		if e.permissionGranted(permissions, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
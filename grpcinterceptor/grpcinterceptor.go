@@ -0,0 +1,124 @@
+// Package grpcinterceptor enforces grole roles and permissions on the methods
+// of a gRPC server, with the decision of the middleware package.
+package grpcinterceptor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMetadataKey is the metadata key holding the user ID by default.
+const DefaultMetadataKey = "x-user-id"
+
+// UserID extracts the caller of a method from its context, ok is false when
+// the call isn't authenticated.
+type UserID func(ctx context.Context) (userID uint, ok bool)
+
+// Read the user ID from the given incoming metadata key. Only use it behind a
+// proxy or an interceptor which authenticates the calls and sets the key itself.
+// @param string
+// @return UserID
+func FromMetadata(key string) UserID {
+	return func(ctx context.Context) (uint, bool) {
+		values := metadata.ValueFromIncomingContext(ctx, key)
+		if len(values) != 1 {
+			return 0, false
+		}
+		return middleware.ParseUserID(values[0])
+	}
+}
+
+type Options struct {
+	// Enforcer checks the requirements, the default enforcer of grole when nil.
+	Enforcer *grole.Enforcer
+	// Registry holds the requirement of every method.
+	Registry *Registry
+	// UserID extracts the caller, FromMetadata(DefaultMetadataKey) by default.
+	UserID UserID
+	// AllowUnregistered lets through the calls of methods missing from the
+	// registry, they are rejected with codes.PermissionDenied otherwise.
+	AllowUnregistered bool
+	// Error converts the errors preventing a check to the error of the call,
+	// a generic codes.Internal status by default.
+	Error func(ctx context.Context, fullMethod string, err error) error
+}
+
+// Interceptor enforces the requirements of the registry on the calls.
+type Interceptor struct {
+	opts       Options
+	middleware *middleware.Middleware
+}
+
+// create an interceptor with the given options
+// @param Options
+// @return *Interceptor
+func New(opt Options) *Interceptor {
+	if opt.Registry == nil {
+		opt.Registry = NewRegistry()
+	}
+	if opt.UserID == nil {
+		opt.UserID = FromMetadata(DefaultMetadataKey)
+	}
+	return &Interceptor{
+		opts:       opt,
+		middleware: middleware.NewMiddleware(middleware.Options{Enforcer: opt.Enforcer}),
+	}
+}
+
+// Return the interceptor of unary calls.
+// @return grpc.UnaryServerInterceptor
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.Authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Return the interceptor of streams, checked once when the stream opens.
+// @return grpc.StreamServerInterceptor
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.Authorize(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// Check the requirement of the method for the caller, return nil or the
+// status error of the call.
+// @param context.Context, string
+// @return error
+func (i *Interceptor) Authorize(ctx context.Context, fullMethod string) error {
+	requirement, ok := i.opts.Registry.Lookup(fullMethod)
+	if !ok {
+		if i.opts.AllowUnregistered {
+			return nil
+		}
+		return status.Errorf(codes.PermissionDenied, "%s has no requirement", fullMethod)
+	}
+
+	userID, authenticated := i.opts.UserID(ctx)
+	err := i.middleware.Decide(ctx, userID, authenticated, requirement)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, middleware.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	case errors.Is(err, middleware.ErrForbidden):
+		return status.Errorf(codes.PermissionDenied, "permission denied to %s", fullMethod)
+	case i.opts.Error != nil:
+		return i.opts.Error(ctx, fullMethod, err)
+	default:
+		return status.Error(codes.Internal, "authorization check failed")
+	}
+}
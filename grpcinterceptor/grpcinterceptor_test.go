@@ -0,0 +1,124 @@
+package grpcinterceptor_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/grpcinterceptor"
+	"github.com/mousav1/grole/middleware"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func dial(t *testing.T, interceptor *grpcinterceptor.Interceptor) healthpb.HealthClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptor.Unary()),
+		grpc.StreamInterceptor(interceptor.Stream()),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func withUser(userID string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), grpcinterceptor.DefaultMetadataKey, userID)
+}
+
+// enforcer return an enforcer on an in-memory database, without grole.New.
+func enforcer(t *testing.T) *grole.Enforcer {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return grole.NewEnforcer(grole.Options{DB: db})
+}
+
+// callerIs is a requirement only met by the given user.
+func callerIs(userID uint) middleware.Requirement {
+	return func(e *grole.Enforcer, caller uint) (bool, error) {
+		return caller == userID, nil
+	}
+}
+
+func TestUnary(t *testing.T) {
+	registry := grpcinterceptor.NewRegistry().Require("/grpc.health.v1.Health/Check", callerIs(1))
+	client := dial(t, grpcinterceptor.New(grpcinterceptor.Options{Enforcer: enforcer(t), Registry: registry}))
+
+	for userID, code := range map[string]codes.Code{
+		"1": codes.OK,
+		"2": codes.PermissionDenied,
+	} {
+		_, err := client.Check(withUser(userID), &healthpb.HealthCheckRequest{})
+		require.Equal(t, code, status.Code(err), userID)
+	}
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestStream(t *testing.T) {
+	registry := grpcinterceptor.NewRegistry().Require("/grpc.health.v1.Health/Watch", callerIs(1))
+	client := dial(t, grpcinterceptor.New(grpcinterceptor.Options{Enforcer: enforcer(t), Registry: registry}))
+
+	stream, err := client.Watch(withUser("1"), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, response.Status)
+
+	for ctx, code := range map[context.Context]codes.Code{
+		withUser("2"):        codes.PermissionDenied,
+		context.Background(): codes.Unauthenticated,
+	} {
+		stream, err = client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, code, status.Code(err))
+	}
+}
+
+func TestNoEnforcer(t *testing.T) {
+	var failure error
+	registry := grpcinterceptor.NewRegistry().Require("/grpc.health.v1.Health/Check", callerIs(1))
+	client := dial(t, grpcinterceptor.New(grpcinterceptor.Options{
+		Registry: registry,
+		Error: func(ctx context.Context, fullMethod string, err error) error {
+			failure = err
+			return status.Error(codes.Unavailable, "unavailable")
+		},
+	}))
+
+	_, err := client.Check(withUser("1"), &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.ErrorIs(t, failure, middleware.ErrNoEnforcer)
+}
+
+func TestUnregistered(t *testing.T) {
+	client := dial(t, grpcinterceptor.New(grpcinterceptor.Options{}))
+	_, err := client.Check(withUser("1"), &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	client = dial(t, grpcinterceptor.New(grpcinterceptor.Options{AllowUnregistered: true}))
+	_, err = client.Check(withUser("3"), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+}
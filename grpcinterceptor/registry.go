@@ -0,0 +1,68 @@
+package grpcinterceptor
+
+import (
+	"sync"
+
+	"github.com/mousav1/grole/middleware"
+)
+
+// Registry maps the full method names, like /billing.v1.Invoices/Create, to
+// the requirement of their callers.
+type Registry struct {
+	mu           sync.RWMutex
+	requirements map[string]middleware.Requirement
+}
+
+// create an empty registry
+// @return *Registry
+func NewRegistry() *Registry {
+	return &Registry{requirements: make(map[string]middleware.Requirement)}
+}
+
+// Set the requirement of the method, replacing the previous one.
+// @param string, middleware.Requirement
+// @return *Registry
+func (r *Registry) Require(fullMethod string, requirement middleware.Requirement) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requirements[fullMethod] = requirement
+	return r
+}
+
+// Require at least one of the given permissions to call the method.
+// @param string, string
+// @return *Registry
+func (r *Registry) RequirePermission(fullMethod string, permissions ...string) *Registry {
+	return r.Require(fullMethod, middleware.AnyPermission(permissions...))
+}
+
+// Require every given permission to call the method.
+// @param string, string
+// @return *Registry
+func (r *Registry) RequireAllPermissions(fullMethod string, permissions ...string) *Registry {
+	return r.Require(fullMethod, middleware.AllPermissions(permissions...))
+}
+
+// Require at least one of the given roles to call the method.
+// @param string, string
+// @return *Registry
+func (r *Registry) RequireAnyRole(fullMethod string, roles ...string) *Registry {
+	return r.Require(fullMethod, middleware.AnyRole(roles...))
+}
+
+// Require every given role to call the method.
+// @param string, string
+// @return *Registry
+func (r *Registry) RequireAllRoles(fullMethod string, roles ...string) *Registry {
+	return r.Require(fullMethod, middleware.AllRoles(roles...))
+}
+
+// Return the requirement of the method.
+// @param string
+// @return middleware.Requirement, bool
+func (r *Registry) Lookup(fullMethod string) (middleware.Requirement, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	requirement, ok := r.requirements[fullMethod]
+	return requirement, ok
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// Wrap the handler so it only serves users meeting the requirement.
+// @param Requirement
+// @return func(http.Handler) http.Handler
+func Require(requirement Requirement) func(http.Handler) http.Handler {
+	return defaultMiddleware.Require(requirement)
+}
+
+// Only serve users having at least one of the given permissions.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return defaultMiddleware.RequirePermission(permissions...)
+}
+
+// Only serve users having every given permission.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequireAllPermissions(permissions ...string) func(http.Handler) http.Handler {
+	return defaultMiddleware.RequireAllPermissions(permissions...)
+}
+
+// Only serve users having at least one of the given roles.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
+	return defaultMiddleware.RequireAnyRole(roles...)
+}
+
+// Only serve users having every given role.
+// @param string
+// @return func(http.Handler) http.Handler
+func RequireAllRoles(roles ...string) func(http.Handler) http.Handler {
+	return defaultMiddleware.RequireAllRoles(roles...)
+}
+
+// Check the requirement for the user of the request.
+// @param *http.Request, Requirement
+// @return error
+func Authorize(r *http.Request, requirement Requirement) error {
+	return defaultMiddleware.Authorize(r, requirement)
+}
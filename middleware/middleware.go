@@ -0,0 +1,215 @@
+// Package middleware enforces grole roles and permissions on net/http handlers.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mousav1/grole"
+)
+
+var (
+	ErrUnauthenticated = errors.New("UNAUTHENTICATED")
+	ErrForbidden       = errors.New("FORBIDDEN")
+	// ErrNoEnforcer is returned when no enforcer is set and grole.New was
+	// never called.
+	ErrNoEnforcer = errors.New("NO ENFORCER")
+)
+
+// Requirement tells whether the user may go through, an error means it
+// couldn't be checked.
+type Requirement func(e *grole.Enforcer, userID uint) (bool, error)
+
+// Require at least one of the given permissions.
+// @param string
+// @return Requirement
+func AnyPermission(permissions ...string) Requirement {
+	return func(e *grole.Enforcer, userID uint) (bool, error) {
+		return e.HasAnyPermissions(userID, permissions...)
+	}
+}
+
+// Require every given permission.
+// @param string
+// @return Requirement
+func AllPermissions(permissions ...string) Requirement {
+	return func(e *grole.Enforcer, userID uint) (bool, error) {
+		return e.HasAllPermission(userID, permissions...)
+	}
+}
+
+// Require at least one of the given roles.
+// @param string
+// @return Requirement
+func AnyRole(roles ...string) Requirement {
+	return func(e *grole.Enforcer, userID uint) (bool, error) {
+		return e.HasAnyRole(userID, roles...)
+	}
+}
+
+// Require every given role.
+// @param string
+// @return Requirement
+func AllRoles(roles ...string) Requirement {
+	return func(e *grole.Enforcer, userID uint) (bool, error) {
+		return e.HasAllRole(userID, roles...)
+	}
+}
+
+type Options struct {
+	// Enforcer checks the requirements, the default enforcer of grole when nil.
+	Enforcer *grole.Enforcer
+	// UserID extracts the user of the request, FromContext by default.
+	UserID UserID
+	// Unauthorized responds to requests without a user, with 401 by default.
+	Unauthorized http.Handler
+	// Forbidden responds to users who don't meet the requirement, with 403 by default.
+	Forbidden http.Handler
+	// Error responds when the requirement couldn't be checked, with 500 by default.
+	Error func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Middleware builds the handlers enforcing requirements with its options.
+type Middleware struct {
+	opts Options
+}
+
+var defaultMiddleware = &Middleware{}
+
+// set the options of the middleware and make it the default one
+// @param Options
+// @return *Middleware
+func New(opt Options) *Middleware {
+	defaultMiddleware = NewMiddleware(opt)
+	return defaultMiddleware
+}
+
+// create a new middleware without changing the default one
+// @param Options
+// @return *Middleware
+func NewMiddleware(opt Options) *Middleware {
+	return &Middleware{opts: opt}
+}
+
+// Return the default middleware, used by the package level functions.
+// @return *Middleware
+func Default() *Middleware {
+	return defaultMiddleware
+}
+
+// Wrap the handler so it only serves users meeting the requirement.
+// @param Requirement
+// @return func(http.Handler) http.Handler
+func (m *Middleware) Require(requirement Requirement) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := m.Authorize(r, requirement); err != nil {
+				m.respond(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Only serve users having at least one of the given permissions.
+// @param string
+// @return func(http.Handler) http.Handler
+func (m *Middleware) RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return m.Require(AnyPermission(permissions...))
+}
+
+// Only serve users having every given permission.
+// @param string
+// @return func(http.Handler) http.Handler
+func (m *Middleware) RequireAllPermissions(permissions ...string) func(http.Handler) http.Handler {
+	return m.Require(AllPermissions(permissions...))
+}
+
+// Only serve users having at least one of the given roles.
+// @param string
+// @return func(http.Handler) http.Handler
+func (m *Middleware) RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
+	return m.Require(AnyRole(roles...))
+}
+
+// Only serve users having every given role.
+// @param string
+// @return func(http.Handler) http.Handler
+func (m *Middleware) RequireAllRoles(roles ...string) func(http.Handler) http.Handler {
+	return m.Require(AllRoles(roles...))
+}
+
+// Check the requirement for the user of the request.
+// @param *http.Request, Requirement
+// @return error
+func (m *Middleware) Authorize(r *http.Request, requirement Requirement) error {
+	userID, ok := m.userID()(r)
+	return m.Decide(r.Context(), userID, ok, requirement)
+}
+
+// Check the requirement for the given user, authenticated is false when the
+// request has no user. Return nil when the user meets the requirement,
+// ErrUnauthenticated or ErrForbidden when they don't, ErrNoEnforcer without an
+// enforcer, or the error of grole preventing the check. The framework adapters share this decision.
+// @param context.Context, uint, bool, Requirement
+// @return error
+func (m *Middleware) Decide(ctx context.Context, userID uint, authenticated bool, requirement Requirement) error {
+	if !authenticated {
+		return ErrUnauthenticated
+	}
+	enforcer := m.opts.Enforcer
+	if enforcer == nil {
+		enforcer = grole.Default()
+	}
+	if enforcer == nil {
+		return ErrNoEnforcer
+	}
+	allowed, err := requirement(enforcer.WithContext(ctx), userID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Return the HTTP status matching an error of Decide.
+// @param error
+// @return int
+func Status(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respond write the response of a request that didn't go through.
+func (m *Middleware) respond(w http.ResponseWriter, r *http.Request, err error) {
+	switch status := Status(err); {
+	case status == http.StatusUnauthorized && m.opts.Unauthorized != nil:
+		m.opts.Unauthorized.ServeHTTP(w, r)
+	case status == http.StatusForbidden && m.opts.Forbidden != nil:
+		m.opts.Forbidden.ServeHTTP(w, r)
+	case status == http.StatusInternalServerError && m.opts.Error != nil:
+		m.opts.Error(w, r, err)
+	default:
+		http.Error(w, http.StatusText(status), status)
+	}
+}
+
+// userID return the user ID extractor of the middleware.
+func (m *Middleware) userID() UserID {
+	if m.opts.UserID != nil {
+		return m.opts.UserID
+	}
+	return FromContext(nil)
+}
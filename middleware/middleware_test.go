@@ -0,0 +1,31 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mousav1/grole/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+// grole.New is never called in this package, so there is no default enforcer.
+func TestNoEnforcer(t *testing.T) {
+	var failure error
+	handler := middleware.NewMiddleware(middleware.Options{
+		UserID: middleware.FromHeader("X-User-ID"),
+		Error: func(w http.ResponseWriter, r *http.Request, err error) {
+			failure = err
+			w.WriteHeader(http.StatusInternalServerError)
+		},
+	}).RequirePermission("invoices.create")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/invoices", nil)
+	r.Header.Set("X-User-ID", "1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.ErrorIs(t, failure, middleware.ErrNoEnforcer)
+}
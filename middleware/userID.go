@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// UserID extracts the user of a request, ok is false when the request isn't
+// authenticated. Any function with this signature can be used as a callback.
+type UserID func(r *http.Request) (userID uint, ok bool)
+
+type userIDKey struct{}
+
+// Return a copy of the context carrying the given user ID, for FromContext.
+// @param context.Context, uint
+// @return context.Context
+func ContextWithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// Read the user ID stored in the request context under key, typically by an
+// authentication middleware, or set by ContextWithUserID when key is nil. The
+// value may be an unsigned or signed integer, or a decimal string.
+// @param interface{}
+// @return UserID
+func FromContext(key interface{}) UserID {
+	if key == nil {
+		key = userIDKey{}
+	}
+	return func(r *http.Request) (uint, bool) {
+		return ParseUserID(r.Context().Value(key))
+	}
+}
+
+// Read the user ID from the given request header. Only use it behind a proxy
+// which authenticates the requests and sets the header itself.
+// @param string
+// @return UserID
+func FromHeader(name string) UserID {
+	return func(r *http.Request) (uint, bool) {
+		return ParseUserID(r.Header.Get(name))
+	}
+}
+
+// Convert a user ID of any integer type, or a decimal string, to a uint.
+// @param interface{}
+// @return uint, bool
+func ParseUserID(value interface{}) (uint, bool) {
+	switch id := value.(type) {
+	case uint:
+		return id, true
+	case uint64:
+		return uint(id), true
+	case uint32:
+		return uint(id), true
+	case int:
+		return uint(id), id >= 0
+	case int64:
+		return uint(id), id >= 0
+	case int32:
+		return uint(id), id >= 0
+	case string:
+		parsed, err := strconv.ParseUint(id, 10, 0)
+		return uint(parsed), err == nil
+	default:
+		return 0, false
+	}
+}
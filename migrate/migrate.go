@@ -1,12 +1,91 @@
-package migrate
-
-import (
-	"github.com/mousav1/grole/models"
-	"gorm.io/gorm"
-)
-
-func MigrateTables(db *gorm.DB) {
-	db.AutoMigrate(&models.Permission{})
-	db.AutoMigrate(&models.Role{})
-	db.AutoMigrate(&models.UserRoles{})
-}
+package migrate
+
+import (
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func MigrateTables(db *gorm.DB) {
+	// the join table of roles and permissions carries the validity of the grants.
+	db.SetupJoinTable(&models.Role{}, "Permissions", &models.PermissionRole{})
+	db.SetupJoinTable(&models.Permission{}, "Roles", &models.PermissionRole{})
+	db.AutoMigrate(&models.Permission{})
+	db.AutoMigrate(&models.Role{})
+	db.AutoMigrate(&models.PermissionRole{})
+	db.AutoMigrate(&models.UserRoles{})
+	scopeUserRolesKey(db)
+	db.AutoMigrate(&models.UserRolesArchive{})
+	db.AutoMigrate(&models.UserPermissions{})
+	db.AutoMigrate(&models.RoleHierarchy{})
+	db.AutoMigrate(&models.ResourcePermission{})
+	db.AutoMigrate(&models.AuditLog{})
+	db.AutoMigrate(&models.AuditChain{})
+	db.AutoMigrate(&models.Outbox{})
+	db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.AuditChain{ID: 1})
+}
+
+// scopeUserRolesKey add scope to the primary key of a user_roles table created
+// before the role assignments were scoped. AutoMigrate only adds the column,
+// so the same role couldn't be assigned to a user in two scopes.
+func scopeUserRolesKey(db *gorm.DB) error {
+	columns, err := db.Migrator().ColumnTypes(&models.UserRoles{})
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Name() != "scope" {
+			continue
+		}
+		if primary, ok := column.PrimaryKey(); !ok || primary {
+			return nil
+		}
+	}
+
+	table := clause.Table{Name: "user_roles"}
+	key := []clause.Column{{Name: "user_id"}, {Name: "role_id"}, {Name: "scope"}}
+	return db.Transaction(func(tx *gorm.DB) error {
+		switch tx.Dialector.Name() {
+		case "postgres":
+			var name string
+			res := tx.Raw("SELECT conname FROM pg_constraint WHERE conrelid = 'user_roles'::regclass AND contype = 'p'").Scan(&name)
+			if res.Error != nil {
+				return res.Error
+			}
+			return tx.Exec("ALTER TABLE ? DROP CONSTRAINT ?, ADD PRIMARY KEY ?", table, clause.Column{Name: name}, key).Error
+		case "mysql":
+			return tx.Exec("ALTER TABLE ? DROP PRIMARY KEY, ADD PRIMARY KEY ?", table, key).Error
+		case "sqlserver":
+			var name string
+			res := tx.Raw("SELECT name FROM sys.key_constraints WHERE type = 'PK' AND parent_object_id = OBJECT_ID('user_roles')").Scan(&name)
+			if res.Error != nil {
+				return res.Error
+			}
+			if err := tx.Exec("ALTER TABLE ? DROP CONSTRAINT ?", table, clause.Column{Name: name}).Error; err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE ? ADD PRIMARY KEY ?", table, key).Error
+		case "sqlite":
+			// the primary key of a SQLite table can't be altered, the table is
+			// created again and the assignments copied.
+			statements := []string{
+				"ALTER TABLE user_roles RENAME TO user_roles_unscoped",
+				"DROP INDEX IF EXISTS idx_user_roles_expires_at",
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Migrator().CreateTable(&models.UserRoles{}); err != nil {
+				return err
+			}
+			if err := tx.Exec("INSERT INTO user_roles (user_id, role_id, scope, valid_from, expires_at) " +
+				"SELECT user_id, role_id, scope, valid_from, expires_at FROM user_roles_unscoped").Error; err != nil {
+				return err
+			}
+			return tx.Exec("DROP TABLE user_roles_unscoped").Error
+		}
+		return nil
+	})
+}
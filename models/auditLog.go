@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// AuditLog records a change of the roles and permissions, who made it and when.
+// UserID, RoleID and PermissionID are zero when the change doesn't concern them,
+// Before and After hold the JSON of the changed values. Hash chains the entry to
+// the previous one, whose hash is PrevHash.
+type AuditLog struct {
+	ID           uint      `gorm:"primaryKey" column:"id"`
+	Actor        string    `gorm:"index;not null;default:''" column:"actor"`
+	Action       string    `gorm:"index;not null" column:"action"`
+	Entity       string    `gorm:"not null" column:"entity"`
+	UserID       uint      `gorm:"index;not null;default:0" column:"user_id"`
+	RoleID       uint      `gorm:"index;not null;default:0" column:"role_id"`
+	PermissionID uint      `gorm:"index;not null;default:0" column:"permission_id"`
+	Scope        string    `gorm:"not null;default:''" column:"scope"`
+	Before       string    `gorm:"type:text" column:"before"`
+	After        string    `gorm:"type:text" column:"after"`
+	CreatedAt    time.Time `gorm:"index" column:"created_at"`
+	PrevHash     string    `gorm:"not null;default:''" column:"prev_hash"`
+	Hash         string    `gorm:"not null;default:''" column:"hash"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// AuditChain is the single row holding the last entry of the audit log chain,
+// it is locked while entries are appended so they are chained one after the other.
+type AuditChain struct {
+	ID     uint   `gorm:"primaryKey" column:"id"`
+	LastID uint   `gorm:"not null;default:0" column:"last_id"`
+	Hash   string `gorm:"not null;default:''" column:"hash"`
+}
+
+func (AuditChain) TableName() string {
+	return "audit_chain"
+}
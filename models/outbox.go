@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Outbox holds a change event written in the transaction making the change, until
+// a relay delivers it. Status is "pending", "delivered" or "dead" once it failed
+// too many times; a pending message is due from NextAttemptAt, which a relay also
+// moves forward while it delivers the message.
+type Outbox struct {
+	ID            uint       `gorm:"primaryKey" column:"id"`
+	Action        string     `gorm:"not null" column:"action"`
+	Payload       string     `gorm:"type:text;not null" column:"payload"`
+	Status        string     `gorm:"index:idx_outbox_due,priority:1;not null" column:"status"`
+	Attempts      int        `gorm:"not null;default:0" column:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_due,priority:2" column:"next_attempt_at"`
+	LastError     string     `gorm:"type:text;not null;default:''" column:"last_error"`
+	CreatedAt     time.Time  `column:"created_at"`
+	DeliveredAt   *time.Time `column:"delivered_at"`
+}
+
+func (Outbox) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PermissionRole grants a permission to a role. The grant is active from
+// ValidFrom until ExpiresAt, a nil bound doesn't limit it.
+type PermissionRole struct {
+	RoleID       uint       `gorm:"primaryKey" column:"role_id"`
+	PermissionID uint       `gorm:"primaryKey" column:"permission_id"`
+	ValidFrom    *time.Time `column:"valid_from"`
+	ExpiresAt    *time.Time `gorm:"index" column:"expires_at"`
+}
+
+func (PermissionRole) TableName() string {
+	return "permission_role"
+}
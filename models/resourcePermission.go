@@ -0,0 +1,17 @@
+package models
+
+// ResourcePermission grants a permission on a single resource, identified by
+// its type and ID, to a user or to a role. UserID is zero for a role and RoleID
+// is zero for a user.
+type ResourcePermission struct {
+	ID           uint   `gorm:"primaryKey" column:"id"`
+	UserID       uint   `gorm:"uniqueIndex:idx_resource_permission,priority:1;not null;default:0" column:"user_id"`
+	RoleID       uint   `gorm:"uniqueIndex:idx_resource_permission,priority:2;not null;default:0" column:"role_id"`
+	PermissionID uint   `gorm:"uniqueIndex:idx_resource_permission,priority:3;not null" column:"permission_id"`
+	ResourceType string `gorm:"uniqueIndex:idx_resource_permission,priority:4;not null" column:"resource_type"`
+	ResourceID   uint   `gorm:"uniqueIndex:idx_resource_permission,priority:5;not null" column:"resource_id"`
+}
+
+func (ResourcePermission) TableName() string {
+	return "resource_permissions"
+}
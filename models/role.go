@@ -4,6 +4,7 @@ type Role struct {
 	ID          uint `gorm:"primary_key, AUTO_INCREMENT"`
 	Name        string
 	Description string
+	Scope       string       `gorm:"not null;default:''"`
 	Permissions []Permission `gorm:"many2many:permission_role;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
@@ -0,0 +1,11 @@
+package models
+
+// RoleHierarchy makes the role inherit every permission of the parent role.
+type RoleHierarchy struct {
+	RoleID   uint `gorm:"primaryKey" column:"role_id"`
+	ParentID uint `gorm:"primaryKey" column:"parent_id"`
+}
+
+func (RoleHierarchy) TableName() string {
+	return "role_hierarchy"
+}
@@ -0,0 +1,10 @@
+package models
+
+type UserPermissions struct {
+	UserID       uint `gorm:"primaryKey" column:"user_id"`
+	PermissionID uint `gorm:"primaryKey" column:"permission_id"`
+}
+
+func (UserPermissions) TableName() string {
+	return "user_permissions"
+}
@@ -1,10 +1,33 @@
 package models
 
+import "time"
+
+// UserRoles assigns a role to a user in a scope. The assignment is active from
+// ValidFrom until ExpiresAt, a nil bound doesn't limit it.
 type UserRoles struct {
-	UserID uint `gorm:"primaryKey" column:"user_id"`
-	RoleID uint `gorm:"primaryKey" column:"role_id"`
+	UserID    uint       `gorm:"primaryKey" column:"user_id"`
+	RoleID    uint       `gorm:"primaryKey" column:"role_id"`
+	Scope     string     `gorm:"primaryKey;not null;default:''" column:"scope"`
+	ValidFrom *time.Time `column:"valid_from"`
+	ExpiresAt *time.Time `gorm:"index" column:"expires_at"`
 }
 
 func (UserRoles) TableName() string {
 	return "user_roles"
 }
+
+// UserRolesArchive keeps the expired role assignments removed from user_roles
+// by ArchiveExpiredRoles.
+type UserRolesArchive struct {
+	ID         uint       `gorm:"primaryKey" column:"id"`
+	UserID     uint       `gorm:"index;not null" column:"user_id"`
+	RoleID     uint       `gorm:"index;not null" column:"role_id"`
+	Scope      string     `gorm:"not null;default:''" column:"scope"`
+	ValidFrom  *time.Time `column:"valid_from"`
+	ExpiresAt  *time.Time `column:"expires_at"`
+	ArchivedAt time.Time  `column:"archived_at"`
+}
+
+func (UserRolesArchive) TableName() string {
+	return "user_roles_archive"
+}
@@ -0,0 +1,143 @@
+package grole
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+// OutboxStatus is the delivery state of an outbox message.
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	// the message failed RelayOptions.MaxAttempts times, RequeueOutbox retries it.
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxMessage is a change event delivered by a Relay. A message may be
+// delivered more than once, the ID tells the duplicates apart.
+type OutboxMessage struct {
+	ID     uint   `json:"id"`
+	Action Action `json:"action"`
+	// Event is the JSON of the event, Decode returns it typed.
+	Event     json.RawMessage `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	// Attempts counts the deliveries of the message, this one included.
+	Attempts int `json:"attempts"`
+}
+
+// Return the event of the message, of the type matching its action.
+// @return Event, error
+func (m OutboxMessage) Decode() (Event, error) {
+	var event Event
+	switch m.Action {
+	case ActionRoleCreated:
+		event = &RoleCreated{}
+	case ActionRoleUpdated:
+		event = &RoleUpdated{}
+	case ActionRoleDeleted:
+		event = &RoleDeleted{}
+	case ActionPermissionCreated:
+		event = &PermissionCreated{}
+	case ActionPermissionUpdated:
+		event = &PermissionUpdated{}
+	case ActionPermissionDeleted:
+		event = &PermissionDeleted{}
+	case ActionRoleAssigned:
+		event = &RoleAssigned{}
+	case ActionRoleRevoked:
+		event = &RoleRevoked{}
+	case ActionRoleExpired:
+		event = &RoleExpired{}
+	case ActionPermissionGranted:
+		event = &PermissionGranted{}
+	case ActionPermissionRevoked:
+		event = &PermissionRevoked{}
+	case ActionParentRoleAdded:
+		event = &ParentRoleAdded{}
+	case ActionParentRoleRemoved:
+		event = &ParentRoleRemoved{}
+	case ActionResourceGranted:
+		event = &ResourceGranted{}
+	case ActionResourceRevoked:
+		event = &ResourceRevoked{}
+	default:
+		return nil, fmt.Errorf("grole: unknown outbox action %q", m.Action)
+	}
+	if err := json.Unmarshal(m.Event, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Return the outbox messages having the given status, oldest first, all of
+// them when limit is zero.
+// @param OutboxStatus, int
+// @return []models.Outbox, error
+func (e *Enforcer) GetOutbox(status OutboxStatus, limit int) ([]models.Outbox, error) {
+	db := e.db.Where("status = ?", string(status))
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	messages := []models.Outbox{}
+	res := db.Order("id").Find(&messages)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return messages, nil
+}
+
+// Deliver the given dead outbox messages again, as if they were never tried.
+// @param uint
+// @return error
+func (e *Enforcer) RequeueOutbox(ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	res := e.db.Model(&models.Outbox{}).
+		Where("id IN ? AND status = ?", ids, string(OutboxDead)).
+		Updates(map[string]interface{}{
+			"status":          string(OutboxPending),
+			"attempts":        0,
+			"next_attempt_at": time.Now().UTC(),
+		})
+	return res.Error
+}
+
+// Delete the outbox messages delivered before the given time and return
+// their number.
+// @param time.Time
+// @return int64, error
+func (e *Enforcer) PurgeOutbox(before time.Time) (int64, error) {
+	res := e.db.Where("status = ? AND delivered_at < ?", string(OutboxDelivered), before.UTC()).Delete(&models.Outbox{})
+	return res.RowsAffected, res.Error
+}
+
+// writeOutbox add a pending message for every change to the outbox, in the
+// transaction making them, when Options.Outbox is set.
+func (e *Enforcer) writeOutbox(db *gorm.DB, changes []change) error {
+	if !e.opts.Outbox || len(changes) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	messages := make([]models.Outbox, 0, len(changes))
+	for _, c := range changes {
+		payload, err := json.Marshal(c.event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, models.Outbox{
+			Action:        string(c.action),
+			Payload:       string(payload),
+			Status:        string(OutboxPending),
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	return db.Create(&messages).Error
+}
@@ -0,0 +1,104 @@
+// Package pgnotify provides a grole.Broadcaster sending cache invalidations
+// with Postgres NOTIFY, for instances sharing the database grole writes to.
+package pgnotify
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mousav1/grole"
+	"gorm.io/gorm"
+)
+
+// DefaultChannel is the channel notified of the invalidations.
+const DefaultChannel = "grole_changes"
+
+// Broadcaster notifies the invalidations on a Postgres channel and listens to
+// the channel with a dedicated connection. It is a grole.TxBroadcaster, the
+// invalidations of a mutation are notified in its transaction.
+type Broadcaster struct {
+	db      *gorm.DB
+	dsn     string
+	channel string
+}
+
+// Create a broadcaster notifying through db and listening with a connection
+// opened from dsn, on the given channel, DefaultChannel when empty.
+// @param *gorm.DB, string, string
+// @return *Broadcaster
+func NewBroadcaster(db *gorm.DB, dsn string, channel string) *Broadcaster {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return &Broadcaster{db: db, dsn: dsn, channel: channel}
+}
+
+func (b *Broadcaster) Publish(ctx context.Context, invalidation grole.Invalidation) error {
+	return b.PublishTx(b.db.WithContext(ctx), invalidation)
+}
+
+// PublishTx notify the invalidation in the transaction of a grole mutation,
+// Postgres only delivers it once the transaction commits.
+func (b *Broadcaster) PublishTx(tx *gorm.DB, invalidation grole.Invalidation) error {
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return err
+	}
+	return tx.Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+// Subscribe deliver the notified invalidations until the context is done.
+// Notifications sent while the connection is lost are missed, so a flush is
+// delivered every time the connection is established again.
+func (b *Broadcaster) Subscribe(ctx context.Context, handle func(grole.Invalidation)) error {
+	connected := false
+	for {
+		err := b.listen(ctx, func() {
+			if connected {
+				handle(grole.Invalidation{})
+			}
+			connected = true
+		}, handle)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && !connected {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// listen open a connection listening to the channel and deliver its
+// notifications until the connection or the context fails.
+func (b *Broadcaster) listen(ctx context.Context, listening func(), handle func(grole.Invalidation)) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return err
+	}
+	listening()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var invalidation grole.Invalidation
+		if err := json.Unmarshal([]byte(notification.Payload), &invalidation); err != nil {
+			handle(grole.Invalidation{})
+			continue
+		}
+		handle(invalidation)
+	}
+}
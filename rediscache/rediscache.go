@@ -0,0 +1,190 @@
+// Package rediscache provides a grole.Cache shared by every instance through
+// Redis, and a grole.Broadcaster sending cache invalidations over Redis pub/sub.
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mousav1/grole"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultPrefix is prepended to every key of the cache.
+	DefaultPrefix = "grole:"
+	// DefaultChannel is the pub/sub channel of the invalidations.
+	DefaultChannel = "grole:invalidations"
+)
+
+// Cache stores the grole cache entries in Redis.
+type Cache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// Create a cache storing its entries in Redis under the given prefix,
+// DefaultPrefix when empty.
+// @param redis.UniversalClient, string
+// @return *Cache
+func NewCache(client redis.UniversalClient, prefix string) *Cache {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &Cache{client: client, prefix: prefix}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+func (c *Cache) DeletePrefix(ctx context.Context, prefix string) error {
+	pattern := escapePattern(c.prefix+prefix) + "*"
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		// SCAN only walks the keys of the node it is sent to.
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return deleteMatching(ctx, node, pattern)
+		})
+	}
+	return deleteMatching(ctx, c.client, pattern)
+}
+
+func (c *Cache) Flush(ctx context.Context) error {
+	return c.DeletePrefix(ctx, "")
+}
+
+// Broadcaster sends the cache invalidations over a Redis pub/sub channel.
+type Broadcaster struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// Create a broadcaster publishing on the given channel, DefaultChannel when empty.
+// @param redis.UniversalClient, string
+// @return *Broadcaster
+func NewBroadcaster(client redis.UniversalClient, channel string) *Broadcaster {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return &Broadcaster{client: client, channel: channel}
+}
+
+func (b *Broadcaster) Publish(ctx context.Context, invalidation grole.Invalidation) error {
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, payload).Err()
+}
+
+// Subscribe deliver the published invalidations until the context is done.
+// Invalidations published while the connection is lost are missed, so a
+// flush is delivered every time the subscription is established again.
+func (b *Broadcaster) Subscribe(ctx context.Context, handle func(grole.Invalidation)) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+	// Receive doesn't watch the context, closing the subscription unblocks it.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			pubsub.Close()
+		case <-done:
+		}
+	}()
+
+	subscribed := false
+	for {
+		message, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch message := message.(type) {
+		case *redis.Subscription:
+			if message.Kind == "subscribe" {
+				if subscribed {
+					handle(grole.Invalidation{})
+				}
+				subscribed = true
+			}
+		case *redis.Message:
+			var invalidation grole.Invalidation
+			if err := json.Unmarshal([]byte(message.Payload), &invalidation); err != nil {
+				handle(grole.Invalidation{})
+				continue
+			}
+			handle(invalidation)
+		}
+	}
+}
+
+// deleteMatching delete the keys of the node matching the pattern. The keys
+// are deleted one by one, a cluster refuses DEL on keys of different slots.
+func deleteMatching(ctx context.Context, node redis.UniversalClient, pattern string) error {
+	iter := node.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 100 {
+			if err := deleteKeys(ctx, node, keys); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return deleteKeys(ctx, node, keys)
+}
+
+// deleteKeys delete the keys in a single round trip.
+func deleteKeys(ctx context.Context, node redis.UniversalClient, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
+
+// escapePattern escape the glob characters of a SCAN pattern.
+func escapePattern(pattern string) string {
+	var escaped strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
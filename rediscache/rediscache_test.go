@@ -0,0 +1,109 @@
+package rediscache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/rediscache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T) *redis.Client {
+	server := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: server.Addr()})
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	cache := rediscache.NewCache(newClient(t), "")
+
+	require.NoError(t, cache.Set(ctx, "user:1:roles:", []byte(`["admin"]`), time.Minute))
+	require.NoError(t, cache.Set(ctx, "user:1:permissions:org-a", []byte(`[]`), time.Minute))
+	require.NoError(t, cache.Set(ctx, "user:12:roles:", []byte(`[]`), time.Minute))
+	require.NoError(t, cache.Set(ctx, `roles:["admin"]:permissions:`, []byte(`[]`), time.Minute))
+
+	value, ok, err := cache.Get(ctx, "user:1:roles:")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, `["admin"]`, string(value))
+
+	require.NoError(t, cache.DeletePrefix(ctx, "user:1:"))
+
+	_, ok, err = cache.Get(ctx, "user:1:permissions:org-a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, ok, err = cache.Get(ctx, "user:12:roles:")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, cache.Flush(ctx))
+
+	_, ok, err = cache.Get(ctx, `roles:["admin"]:permissions:`)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestClusterCache(t *testing.T) {
+	ctx := context.Background()
+	first, second := miniredis.RunT(t), miniredis.RunT(t)
+	client := redis.NewClusterClient(&redis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]redis.ClusterSlot, error) {
+			return []redis.ClusterSlot{
+				{Start: 0, End: 8191, Nodes: []redis.ClusterNode{{Addr: first.Addr()}}},
+				{Start: 8192, End: 16383, Nodes: []redis.ClusterNode{{Addr: second.Addr()}}},
+			}, nil
+		},
+	})
+	t.Cleanup(func() { client.Close() })
+	cache := rediscache.NewCache(client, "")
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, cache.Set(ctx, fmt.Sprintf("user:%d:roles:", i), []byte(`[]`), time.Minute))
+	}
+	require.NotEmpty(t, first.Keys())
+	require.NotEmpty(t, second.Keys())
+
+	require.NoError(t, cache.Flush(ctx))
+	require.Empty(t, first.Keys())
+	require.Empty(t, second.Keys())
+}
+
+func TestBroadcaster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	broadcaster := rediscache.NewBroadcaster(newClient(t), "")
+
+	received := make(chan grole.Invalidation, 1)
+	done := make(chan error)
+	go func() {
+		done <- broadcaster.Subscribe(ctx, func(invalidation grole.Invalidation) {
+			select {
+			case received <- invalidation:
+			default:
+			}
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		err := broadcaster.Publish(ctx, grole.Invalidation{Entity: grole.EntityUser, UserID: 42, Origin: "a"})
+		require.NoError(t, err)
+		select {
+		case invalidation := <-received:
+			require.Equal(t, grole.EntityUser, invalidation.Entity)
+			require.Equal(t, uint(42), invalidation.UserID)
+			require.Equal(t, "a", invalidation.Origin)
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,258 @@
+package grole
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultRelayBatchSize is the number of messages a relay reads at once.
+	DefaultRelayBatchSize = 100
+	// DefaultRelayPollInterval is the time a relay waits when no message is due.
+	DefaultRelayPollInterval = time.Second
+	// DefaultRelayMaxAttempts is the number of failed deliveries after which a
+	// message is dead.
+	DefaultRelayMaxAttempts = 10
+	// DefaultRelayTimeout bounds a delivery, the message is tried again once
+	// it expires, even by another relay.
+	DefaultRelayTimeout = 30 * time.Second
+)
+
+// Sink receives the messages of a relay. A message is delivered once Deliver
+// returns nil, and tried again later otherwise.
+type Sink interface {
+	Deliver(ctx context.Context, message OutboxMessage) error
+}
+
+// SinkFunc delivers the messages with a callback.
+type SinkFunc func(ctx context.Context, message OutboxMessage) error
+
+func (f SinkFunc) Deliver(ctx context.Context, message OutboxMessage) error {
+	return f(ctx, message)
+}
+
+// Return a sink sending the messages on the channel, a message is delivered
+// once it is received.
+// @param chan<- OutboxMessage
+// @return Sink
+func ChannelSink(ch chan<- OutboxMessage) Sink {
+	return SinkFunc(func(ctx context.Context, message OutboxMessage) error {
+		select {
+		case ch <- message:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// WebhookSink posts every message as JSON to an URL, with its ID in the
+// Idempotency-Key header. Any status but 2xx is a failed delivery.
+type WebhookSink struct {
+	URL string
+	// Client sends the requests, http.DefaultClient when nil.
+	Client *http.Client
+	// Header is added to every request, e.g. for authentication.
+	Header http.Header
+}
+
+// Return a sink posting the messages to the URL.
+// @param string
+// @return *WebhookSink
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url}
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, message OutboxMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range s.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strconv.FormatUint(uint64(message.ID), 10))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("grole: webhook responded %s", res.Status)
+	}
+	return nil
+}
+
+type RelayOptions struct {
+	// Sink receives the messages.
+	Sink Sink
+	// BatchSize is DefaultRelayBatchSize when zero.
+	BatchSize int
+	// PollInterval is DefaultRelayPollInterval when zero.
+	PollInterval time.Duration
+	// MaxAttempts is DefaultRelayMaxAttempts when zero.
+	MaxAttempts int
+	// Timeout is DefaultRelayTimeout when zero.
+	Timeout time.Duration
+	// Backoff return the delay before the next delivery of a message which
+	// failed the given number of times, ExponentialBackoff by default.
+	Backoff func(attempts int) time.Duration
+}
+
+// Relay delivers the messages of the outbox to a sink, at least once: a
+// message is only marked delivered after the sink accepted it, so a crash in
+// between delivers it again. Several relays can share an outbox.
+type Relay struct {
+	e    *Enforcer
+	opts RelayOptions
+}
+
+// Return a relay delivering the outbox of the enforcer to the sink.
+// @param RelayOptions
+// @return *Relay
+func (e *Enforcer) NewRelay(opt RelayOptions) *Relay {
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = DefaultRelayBatchSize
+	}
+	if opt.PollInterval <= 0 {
+		opt.PollInterval = DefaultRelayPollInterval
+	}
+	if opt.MaxAttempts <= 0 {
+		opt.MaxAttempts = DefaultRelayMaxAttempts
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = DefaultRelayTimeout
+	}
+	if opt.Backoff == nil {
+		opt.Backoff = ExponentialBackoff
+	}
+	return &Relay{e: e, opts: opt}
+}
+
+// Return a delay of one second doubling with every attempt, up to an hour.
+// @param int
+// @return time.Duration
+func ExponentialBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 13 {
+		return time.Hour
+	}
+	if delay := time.Second << uint(attempts-1); delay < time.Hour {
+		return delay
+	}
+	return time.Hour
+}
+
+// Deliver the due messages until the context is done, and return its error.
+// The errors of the database are passed to Options.OnError.
+// @param context.Context
+// @return error
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		count, err := r.Deliver(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.e.reportError(err)
+		if err == nil && count == r.opts.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// Deliver one batch of due messages and return the number of messages read.
+// A failed delivery isn't an error, the message is tried again later.
+// @param context.Context
+// @return int, error
+func (r *Relay) Deliver(ctx context.Context) (int, error) {
+	db := r.e.db.WithContext(ctx)
+	messages := []models.Outbox{}
+	res := db.Where("status = ? AND next_attempt_at <= ?", string(OutboxPending), time.Now().UTC()).
+		Order("id").Limit(r.opts.BatchSize).Find(&messages)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	for _, message := range messages {
+		if err := r.deliver(ctx, db, message); err != nil {
+			return len(messages), err
+		}
+	}
+	return len(messages), nil
+}
+
+// deliver claim the message, pass it to the sink and record the outcome.
+func (r *Relay) deliver(ctx context.Context, db *gorm.DB, message models.Outbox) error {
+	// the claim counts the attempt and holds the message until the timeout,
+	// it fails when another relay claimed it first.
+	now := time.Now().UTC()
+	res := db.Model(&models.Outbox{}).
+		Where("id = ? AND status = ? AND attempts = ?", message.ID, string(OutboxPending), message.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        message.Attempts + 1,
+			"next_attempt_at": now.Add(r.opts.Timeout),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+	message.Attempts++
+
+	deliverCtx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	err := r.opts.Sink.Deliver(deliverCtx, OutboxMessage{
+		ID:        message.ID,
+		Action:    Action(message.Action),
+		Event:     json.RawMessage(message.Payload),
+		CreatedAt: message.CreatedAt,
+		Attempts:  message.Attempts,
+	})
+	cancel()
+
+	now = time.Now().UTC()
+	update := map[string]interface{}{}
+	switch {
+	case err == nil:
+		update["status"] = string(OutboxDelivered)
+		update["delivered_at"] = now
+		update["last_error"] = ""
+	case message.Attempts >= r.opts.MaxAttempts:
+		update["status"] = string(OutboxDead)
+		update["last_error"] = err.Error()
+	default:
+		update["next_attempt_at"] = now.Add(r.opts.Backoff(message.Attempts))
+		update["last_error"] = err.Error()
+	}
+	// the outcome is recorded even when ctx is done, the message would be
+	// delivered again otherwise, unless another relay claimed it since.
+	res = r.e.db.WithContext(context.Background()).Model(&models.Outbox{}).
+		Where("id = ? AND attempts = ?", message.ID, message.Attempts).Updates(update)
+	return res.Error
+}
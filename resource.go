@@ -0,0 +1,180 @@
+package grole
+
+import (
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+// Grant the permission on a single resource, identified by its type and ID,
+// directly to the user.
+// @param uint, string, string, uint
+// @return bool, error
+func (e *Enforcer) GrantOn(userID uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return e.grantOn(userID, 0, permission, resourceType, resourceID)
+}
+
+// Revoke the permission on the resource granted directly to the user.
+// @param uint, string, string, uint
+// @return bool, error
+func (e *Enforcer) RevokeOn(userID uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return e.revokeOn(userID, 0, permission, resourceType, resourceID)
+}
+
+// Grant the permission on a single resource, identified by its type and ID,
+// to the role and so to every user having it.
+// @param uint, string, string, uint
+// @return bool, error
+func (e *Enforcer) GrantRoleOn(roleId uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return e.grantOn(0, roleId, permission, resourceType, resourceID)
+}
+
+// Revoke the permission on the resource granted to the role.
+// @param uint, string, string, uint
+// @return bool, error
+func (e *Enforcer) RevokeRoleOn(roleId uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	return e.revokeOn(0, roleId, permission, resourceType, resourceID)
+}
+
+// Determine if the user has the permission on the resource, granted directly
+// or to one of its roles, inherited roles included.
+// @param uint, string, string, uint
+// @return bool, error
+func (e *Enforcer) CanOn(userID uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	grants, err := e.resourceGrants(userID, permission, resourceType)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	res := grants.Where("resource_permissions.resource_id = ?", resourceID).Count(&count)
+	if res.Error != nil {
+		return false, wrapError(EntityUser, userID, res.Error)
+	}
+	return count > 0, nil
+}
+
+// Return the IDs of the resources of the given type the user has the
+// permission on, granted directly or to one of its roles.
+// @param uint, string, string
+// @return []uint, error
+func (e *Enforcer) GetResourceIDs(userID uint, permission string, resourceType string) ([]uint, error) {
+	grants, err := e.resourceGrants(userID, permission, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	ids := []uint{}
+	res := grants.Distinct("resource_permissions.resource_id").Order("resource_permissions.resource_id").
+		Pluck("resource_permissions.resource_id", &ids)
+	if res.Error != nil {
+		return nil, wrapError(EntityUser, userID, res.Error)
+	}
+	return ids, nil
+}
+
+// grantOn grant the permission on the resource to the user, or the role when
+// userID is zero.
+func (e *Enforcer) grantOn(userID uint, roleId uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		role, permissionModel, err := tx.resourceGrantee(userID, roleId, permission)
+		if err != nil {
+			return err
+		}
+		created, err := insertMissing(tx.db, &models.ResourcePermission{
+			UserID:       userID,
+			RoleID:       roleId,
+			PermissionID: permissionModel.ID,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+		}, "user_id = ? AND role_id = ? AND permission_id = ? AND resource_type = ? AND resource_id = ?",
+			userID, roleId, permissionModel.ID, resourceType, resourceID)
+		if err != nil {
+			return wrapError(EntityPermission, permission, err)
+		}
+		if created {
+			tx.changed(resourceChange(ActionResourceGranted, userID, role, permissionModel, resourceType, resourceID))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// revokeOn revoke the permission on the resource from the user, or the role
+// when userID is zero.
+func (e *Enforcer) revokeOn(userID uint, roleId uint, permission string, resourceType string, resourceID uint) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		role, permissionModel, err := tx.resourceGrantee(userID, roleId, permission)
+		if err != nil {
+			return err
+		}
+		res := tx.db.Where("user_id = ? AND role_id = ? AND permission_id = ?", userID, roleId, permissionModel.ID).
+			Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+			Delete(&models.ResourcePermission{})
+		if res.Error != nil {
+			return wrapError(EntityPermission, permission, res.Error)
+		} else if res.RowsAffected < 1 {
+			return newError(EntityPermission, permission, ErrPermissionNotAssigned)
+		}
+		tx.changed(resourceChange(ActionResourceRevoked, userID, role, permissionModel, resourceType, resourceID))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// resourceGrantee find the role, when roleId isn't zero, and the permission of
+// a resource grant.
+func (e *Enforcer) resourceGrantee(userID uint, roleId uint, permission string) (models.Role, models.Permission, error) {
+	var role models.Role
+	if userID == 0 {
+		res := e.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return role, models.Permission{}, wrapError(EntityRole, roleId, res.Error)
+		}
+	}
+	permissions, err := e.findPermissionsByName([]string{permission})
+	if err != nil {
+		return role, models.Permission{}, err
+	}
+	return role, permissions[0], nil
+}
+
+// resourceGrants build the query selecting the grants of the permission on
+// resources of the type to the user and to its active roles.
+func (e *Enforcer) resourceGrants(userID uint, permission string, resourceType string) (*gorm.DB, error) {
+	roleIds, err := e.effectiveRoleIDs(userID)
+	if err != nil {
+		return nil, wrapError(EntityUser, userID, err)
+	}
+	// role grants are stored with a zero user_id, which must not match user 0.
+	grantees := e.db.Where("resource_permissions.user_id = ? AND resource_permissions.role_id = 0", userID)
+	if len(roleIds) > 0 {
+		grantees = grantees.Or("resource_permissions.role_id IN ?", roleIds)
+	}
+	return e.db.Model(&models.ResourcePermission{}).
+		Joins("JOIN permissions ON permissions.id = resource_permissions.permission_id").
+		Where("permissions.name = ?", permission).
+		Where("resource_permissions.resource_type = ?", resourceType).
+		Where(grantees), nil
+}
+
+// effectiveRoleIDs return the ids of the roles actively assigned to the user
+// and of the roles they inherit from.
+func (e *Enforcer) effectiveRoleIDs(userID uint) ([]uint, error) {
+	var roleIds []uint
+	res := e.userRoleIDs(userID).Scan(&roleIds)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	graph, err := e.roleGraph()
+	if err != nil {
+		return nil, err
+	}
+	if len(graph) == 0 {
+		return roleIds, nil
+	}
+	return graph.withAncestors(roleIds), nil
+}
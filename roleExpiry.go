@@ -0,0 +1,382 @@
+package grole
+
+import (
+	"time"
+
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+// Assign the given roles to the user until expiresAt, when the assignments
+// stop counting. An existing assignment takes the new expiry.
+// @param uint, time.Time, string
+// @return bool, error
+func (e *Enforcer) AssignRolesUntil(userID uint, expiresAt time.Time, Roles ...string) (bool, error) {
+	return e.assignRoles(userID, time.Time{}, expiresAt, Roles)
+}
+
+// Assign the given roles to the user from validFrom until expiresAt, a zero
+// time doesn't bound the assignments. An existing assignment takes the new
+// validity, an empty one returns ErrInvalidValidity.
+// @param uint, time.Time, time.Time, string
+// @return bool, error
+func (e *Enforcer) AssignRolesBetween(userID uint, validFrom time.Time, expiresAt time.Time, Roles ...string) (bool, error) {
+	return e.assignRoles(userID, validFrom, expiresAt, Roles)
+}
+
+// assignRoles assign the roles to the user for the validity window, or
+// update the window of the existing assignments.
+func (e *Enforcer) assignRoles(userID uint, validFrom time.Time, expiresAt time.Time, Roles []string) (bool, error) {
+	if !validFrom.IsZero() && !expiresAt.IsZero() && !expiresAt.After(validFrom) {
+		return false, newError(EntityUser, userID, ErrInvalidValidity)
+	}
+	from, until := timeBound(validFrom), timeBound(expiresAt)
+	err := e.transaction(func(tx *Enforcer) error {
+		roles, err := tx.findRolesByName(Roles)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			var current []models.UserRoles
+			res := tx.db.Where("user_id = ? AND role_id = ? AND scope = ?", userID, role.ID, tx.scope).Limit(1).Find(&current)
+			if res.Error != nil {
+				return wrapError(EntityUser, userID, res.Error)
+			}
+			if len(current) == 0 {
+				res = tx.db.Create(&models.UserRoles{
+					UserID:    userID,
+					RoleID:    role.ID,
+					Scope:     tx.scope,
+					ValidFrom: from,
+					ExpiresAt: until,
+				})
+			} else if sameTime(current[0].ValidFrom, from) && sameTime(current[0].ExpiresAt, until) {
+				continue
+			} else {
+				res = tx.db.Model(&models.UserRoles{}).
+					Where("user_id = ? AND role_id = ? AND scope = ?", userID, role.ID, tx.scope).
+					Updates(map[string]interface{}{"valid_from": from, "expires_at": until})
+			}
+			if res.Error != nil {
+				return wrapError(EntityUser, userID, res.Error)
+			}
+			tx.changed(roleAssigned(userID, role, from, until))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Grant the given permissions to the role until expiresAt, when the grants
+// stop counting. An existing grant takes the new expiry.
+// @param uint, time.Time, string
+// @return []models.Permission, error
+func (e *Enforcer) AssignPermissionsFromRoleUntil(roleId uint, expiresAt time.Time, permissions ...string) ([]models.Permission, error) {
+	return e.assignPermissionsFromRole(roleId, time.Time{}, expiresAt, permissions)
+}
+
+// Grant the given permissions to the role from validFrom until expiresAt, a
+// zero time doesn't bound the grants. An existing grant takes the new
+// validity, an empty one returns ErrInvalidValidity.
+// @param uint, time.Time, time.Time, string
+// @return []models.Permission, error
+func (e *Enforcer) AssignPermissionsFromRoleBetween(roleId uint, validFrom time.Time, expiresAt time.Time, permissions ...string) ([]models.Permission, error) {
+	return e.assignPermissionsFromRole(roleId, validFrom, expiresAt, permissions)
+}
+
+// assignPermissionsFromRole grant the permissions to the role for the
+// validity window, or update the window of the existing grants.
+func (e *Enforcer) assignPermissionsFromRole(roleId uint, validFrom time.Time, expiresAt time.Time, permissions []string) ([]models.Permission, error) {
+	if !validFrom.IsZero() && !expiresAt.IsZero() && !expiresAt.After(validFrom) {
+		return nil, newError(EntityRole, roleId, ErrInvalidValidity)
+	}
+	from, until := timeBound(validFrom), timeBound(expiresAt)
+	var permissionModels []models.Permission
+	err := e.transaction(func(tx *Enforcer) error {
+		var role models.Role
+		res := tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+
+		var err error
+		permissionModels, err = tx.findPermissionsByName(permissions)
+		if err != nil {
+			return err
+		}
+		return tx.grantPermissions(role, permissionModels, from, until)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return permissionModels, nil
+}
+
+// grantPermissions grant the permissions to the role for the validity window,
+// or update the window of the existing grants.
+func (e *Enforcer) grantPermissions(role models.Role, permissions []models.Permission, from *time.Time, until *time.Time) error {
+	for _, permission := range permissions {
+		var current []models.PermissionRole
+		res := e.db.Where("role_id = ? AND permission_id = ?", role.ID, permission.ID).Limit(1).Find(&current)
+		if res.Error != nil {
+			return wrapError(EntityRole, role.ID, res.Error)
+		}
+		if len(current) == 0 {
+			res = e.db.Create(&models.PermissionRole{
+				RoleID:       role.ID,
+				PermissionID: permission.ID,
+				ValidFrom:    from,
+				ExpiresAt:    until,
+			})
+		} else if sameTime(current[0].ValidFrom, from) && sameTime(current[0].ExpiresAt, until) {
+			continue
+		} else {
+			res = e.db.Model(&models.PermissionRole{}).
+				Where("role_id = ? AND permission_id = ?", role.ID, permission.ID).
+				Updates(map[string]interface{}{"valid_from": from, "expires_at": until})
+		}
+		if res.Error != nil {
+			return wrapError(EntityRole, role.ID, res.Error)
+		}
+		e.changed(permissionGranted(role, permission, from, until))
+	}
+	return nil
+}
+
+// Delete the role assignments which expired and return their number.
+// @return int64, error
+func (e *Enforcer) PurgeExpiredRoles() (int64, error) {
+	return e.removeExpiredRoles(false)
+}
+
+// Move the role assignments which expired to the user_roles_archive table
+// and return their number.
+// @return int64, error
+func (e *Enforcer) ArchiveExpiredRoles() (int64, error) {
+	return e.removeExpiredRoles(true)
+}
+
+// removeExpiredRoles remove the expired role assignments of every scope,
+// archiving them first when asked to.
+func (e *Enforcer) removeExpiredRoles(archive bool) (int64, error) {
+	var removed int64
+	err := e.transaction(func(tx *Enforcer) error {
+		now := time.Now().UTC()
+		var expired []models.UserRoles
+		res := tx.db.Where("expires_at <= ?", now).Order("user_id, role_id").Find(&expired)
+		if res.Error != nil {
+			return res.Error
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		var roleIds []uint
+		for _, userRole := range expired {
+			roleIds = append(roleIds, userRole.RoleID)
+		}
+		roles, err := tx.findRolesById(roleIds)
+		if err != nil {
+			return err
+		}
+		names := make(map[uint]models.Role, len(roles))
+		for _, role := range roles {
+			names[role.ID] = role
+		}
+
+		for _, userRole := range expired {
+			if archive {
+				res = tx.db.Create(&models.UserRolesArchive{
+					UserID:     userRole.UserID,
+					RoleID:     userRole.RoleID,
+					Scope:      userRole.Scope,
+					ValidFrom:  userRole.ValidFrom,
+					ExpiresAt:  userRole.ExpiresAt,
+					ArchivedAt: now,
+				})
+				if res.Error != nil {
+					return wrapError(EntityUser, userRole.UserID, res.Error)
+				}
+			}
+			res = tx.db.Where("user_id = ? AND role_id = ? AND scope = ?", userRole.UserID, userRole.RoleID, userRole.Scope).
+				Delete(&models.UserRoles{})
+			if res.Error != nil {
+				return wrapError(EntityUser, userRole.UserID, res.Error)
+			}
+			role, ok := names[userRole.RoleID]
+			if !ok {
+				role = models.Role{ID: userRole.RoleID}
+			}
+			// the change is recorded in the scope of the assignment.
+			tx.WithScope(userRole.Scope).changed(roleExpired(userRole.UserID, role, *userRole.ExpiresAt))
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// active restrict the query to the rows of the table, user_roles or
+// permission_role, whose validity window contains now.
+func active(db *gorm.DB, table string) *gorm.DB {
+	now := time.Now().UTC()
+	return db.Where("("+table+".valid_from IS NULL OR "+table+".valid_from <= ?)", now).
+		Where("("+table+".expires_at IS NULL OR "+table+".expires_at > ?)", now)
+}
+
+// activeGrants return the active grants whose column, role_id or
+// permission_id, is one of ids.
+func (e *Enforcer) activeGrants(column string, ids []uint) ([]models.PermissionRole, error) {
+	var grants []models.PermissionRole
+	if len(ids) == 0 {
+		return grants, nil
+	}
+	res := active(e.db.Where(column+" IN ?", ids), "permission_role").Order("role_id, permission_id").Find(&grants)
+	return grants, res.Error
+}
+
+// preloadPermissions set the permissions actively granted to the roles. The
+// Permissions preload of gorm can't filter the grants and would list the
+// expired and future ones.
+func (e *Enforcer) preloadPermissions(roles []models.Role) error {
+	roleIds := make([]uint, len(roles))
+	for i, role := range roles {
+		roleIds[i] = role.ID
+	}
+	grants, err := e.activeGrants("role_id", roleIds)
+	if err != nil {
+		return err
+	}
+	permissionIds := make([]uint, len(grants))
+	for i, grant := range grants {
+		permissionIds[i] = grant.PermissionID
+	}
+	var permissions []models.Permission
+	if len(permissionIds) > 0 {
+		if res := e.db.Where("id IN ?", permissionIds).Find(&permissions); res.Error != nil {
+			return res.Error
+		}
+	}
+	byId := make(map[uint]models.Permission, len(permissions))
+	for _, permission := range permissions {
+		byId[permission.ID] = permission
+	}
+	granted := make(map[uint][]models.Permission)
+	for _, grant := range grants {
+		granted[grant.RoleID] = append(granted[grant.RoleID], byId[grant.PermissionID])
+	}
+	for i := range roles {
+		roles[i].Permissions = granted[roles[i].ID]
+	}
+	return nil
+}
+
+// preloadRoles set the roles the permissions are actively granted to, like
+// preloadPermissions.
+func (e *Enforcer) preloadRoles(permissions []models.Permission) error {
+	permissionIds := make([]uint, len(permissions))
+	for i, permission := range permissions {
+		permissionIds[i] = permission.ID
+	}
+	grants, err := e.activeGrants("permission_id", permissionIds)
+	if err != nil {
+		return err
+	}
+	roleIds := make([]uint, len(grants))
+	for i, grant := range grants {
+		roleIds[i] = grant.RoleID
+	}
+	var roles []models.Role
+	if len(roleIds) > 0 {
+		if res := e.db.Where("id IN ?", roleIds).Find(&roles); res.Error != nil {
+			return res.Error
+		}
+	}
+	byId := make(map[uint]models.Role, len(roles))
+	for _, role := range roles {
+		byId[role.ID] = role
+	}
+	granted := make(map[uint][]models.Role)
+	for _, grant := range grants {
+		granted[grant.PermissionID] = append(granted[grant.PermissionID], byId[grant.RoleID])
+	}
+	for i := range permissions {
+		permissions[i].Roles = granted[permissions[i].ID]
+	}
+	return nil
+}
+
+// countActiveGrants return the number of active grants whose column, role_id
+// or permission_id, is id.
+func (e *Enforcer) countActiveGrants(column string, id uint) (int64, error) {
+	var count int64
+	res := active(e.db.Model(&models.PermissionRole{}).Where(column+" = ?", id), "permission_role").Count(&count)
+	return count, res.Error
+}
+
+// nextRoleChange return the next time a role assignment of the user starts
+// or expires, zero when none will.
+func (e *Enforcer) nextRoleChange(userID uint) (time.Time, error) {
+	now := time.Now().UTC()
+	var userRoles []models.UserRoles
+	res := e.db.Where("user_id = ?", userID).Where("scope IN ?", e.scopes()).
+		Where("(valid_from > ? OR expires_at > ?)", now, now).Find(&userRoles)
+	if res.Error != nil {
+		return time.Time{}, res.Error
+	}
+	var next time.Time
+	for _, userRole := range userRoles {
+		next = earliest(next, now, userRole.ValidFrom, userRole.ExpiresAt)
+	}
+	return next, nil
+}
+
+// nextGrantChange return the next time a permission grant of any role starts
+// or expires, zero when none will.
+func (e *Enforcer) nextGrantChange() (time.Time, error) {
+	now := time.Now().UTC()
+	var grants []models.PermissionRole
+	res := e.db.Where("(valid_from > ? OR expires_at > ?)", now, now).Find(&grants)
+	if res.Error != nil {
+		return time.Time{}, res.Error
+	}
+	var next time.Time
+	for _, grant := range grants {
+		next = earliest(next, now, grant.ValidFrom, grant.ExpiresAt)
+	}
+	return next, nil
+}
+
+// earliest return the earliest of next and the bounds after now, a zero next
+// is later than every bound.
+func earliest(next time.Time, now time.Time, bounds ...*time.Time) time.Time {
+	for _, bound := range bounds {
+		if bound != nil && bound.After(now) && (next.IsZero() || bound.Before(next)) {
+			next = *bound
+		}
+	}
+	return next
+}
+
+// timeBound return the bound stored for t, nil when it is zero. The time is
+// stored with the precision of every database so it compares once read back.
+func timeBound(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC().Truncate(time.Microsecond)
+	return &t
+}
+
+// sameTime tell whether two bounds are equal, nil ones included.
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,217 @@
+package grole
+
+import (
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+// Make the role inherit the roles and permissions of the given parent roles.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) AddParentRole(roleId uint, parents ...string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		var role models.Role
+		res := tx.db.Where("id = ?", roleId).First(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+
+		parentRoles, err := tx.findRolesByName(parents)
+		if err != nil {
+			return err
+		}
+
+		graph, err := tx.roleGraph()
+		if err != nil {
+			return err
+		}
+		for _, parent := range parentRoles {
+			// a cycle is created when the role is already an ancestor of the new parent.
+			if parent.ID == role.ID || graph.ancestors(parent.ID)[role.ID] {
+				return newError(EntityRole, parent.Name, ErrRoleHierarchyCycle)
+			}
+
+			created, err := insertMissing(tx.db, &models.RoleHierarchy{
+				RoleID:   role.ID,
+				ParentID: parent.ID,
+			}, "role_id = ? AND parent_id = ?", role.ID, parent.ID)
+			if err != nil {
+				return wrapError(EntityRole, roleId, err)
+			}
+			if created {
+				graph[role.ID] = append(graph[role.ID], parent.ID)
+				tx.changed(parentRoleChange(ActionParentRoleAdded, role, parent))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Stop the role from inheriting the given parent roles.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) RemoveParentRole(roleId uint, parents ...string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		role := models.Role{ID: roleId}
+		res := tx.db.Where("id = ?", roleId).Limit(1).Find(&role)
+		if res.Error != nil {
+			return wrapError(EntityRole, roleId, res.Error)
+		}
+
+		parentRoles, err := tx.findRolesByName(parents)
+		if err != nil {
+			return err
+		}
+		for _, parent := range parentRoles {
+			res := tx.db.Where("role_id = ?", roleId).Where("parent_id = ?", parent.ID).Delete(&models.RoleHierarchy{})
+			if res.Error != nil {
+				return wrapError(EntityRole, roleId, res.Error)
+			} else if res.RowsAffected < 1 {
+				return newError(EntityRole, parent.Name, ErrRoleNotAssigned)
+			}
+			tx.changed(parentRoleChange(ActionParentRoleRemoved, role, parent))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Return the direct parent roles of the role.
+// @param uint
+// @return []models.Role, error
+func (e *Enforcer) GetParentRoles(roleId uint) ([]models.Role, error) {
+	var roles []models.Role
+	res := e.db.Joins("JOIN role_hierarchy ON role_hierarchy.parent_id = roles.id").
+		Where("role_hierarchy.role_id = ?", roleId).
+		Find(&roles)
+	if res.Error != nil {
+		return nil, wrapError(EntityRole, roleId, res.Error)
+	}
+	return roles, nil
+}
+
+// Return the roles the role inherits from, directly or through other roles.
+// @param uint
+// @return []models.Role, error
+func (e *Enforcer) GetInheritedRoles(roleId uint) ([]models.Role, error) {
+	graph, err := e.roleGraph()
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for id := range graph.ancestors(roleId) {
+		ids = append(ids, id)
+	}
+	return e.findRolesById(ids)
+}
+
+// Return all the Roles the user, the assigned ones and the ones they inherit from.
+// @param uint
+// @return []models.Role, error
+func (e *Enforcer) GetAllRoles(userID uint) ([]models.Role, error) {
+	return cachedUntil(e, userCacheKey(userID, "roles"), e.userCacheExpiry(userID), func() ([]models.Role, error) {
+		return e.getAllRoles(userID)
+	})
+}
+
+// getAllRoles load the effective roles of the user from the database.
+func (e *Enforcer) getAllRoles(userID uint) ([]models.Role, error) {
+	graph, err := e.roleGraph()
+	if err != nil {
+		return nil, wrapError(EntityUser, userID, err)
+	}
+	if len(graph) == 0 {
+		return e.GetRole(userID)
+	}
+
+	var roleIds []uint
+	res := e.userRoleIDs(userID).Scan(&roleIds)
+	if res.Error != nil {
+		return nil, wrapError(EntityUser, userID, res.Error)
+	}
+	roles, err := e.findRolesById(graph.withAncestors(roleIds))
+	if err != nil {
+		return nil, wrapError(EntityUser, userID, err)
+	}
+	return roles, nil
+}
+
+// roleGraph map each role id to the ids of its parent roles.
+type roleGraph map[uint][]uint
+
+// roleGraph load the whole role hierarchy with a single query.
+func (e *Enforcer) roleGraph() (roleGraph, error) {
+	var hierarchy []models.RoleHierarchy
+	res := e.db.Find(&hierarchy)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	graph := make(roleGraph, len(hierarchy))
+	for _, h := range hierarchy {
+		graph[h.RoleID] = append(graph[h.RoleID], h.ParentID)
+	}
+	return graph, nil
+}
+
+// ancestors return the ids of every role the given role inherits from.
+func (g roleGraph) ancestors(roleId uint) map[uint]bool {
+	visited := make(map[uint]bool)
+	stack := append([]uint{}, g[roleId]...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, g[id]...)
+	}
+	return visited
+}
+
+// withAncestors return the given role ids and the ids of every role they inherit from.
+func (g roleGraph) withAncestors(roleIds []uint) []uint {
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, id := range roleIds {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		for ancestor := range g.ancestors(id) {
+			if !seen[ancestor] {
+				seen[ancestor] = true
+				ids = append(ids, ancestor)
+			}
+		}
+	}
+	return ids
+}
+
+// userRoleIDs build the query selecting the ids of the roles actively
+// assigned to the user in the enforcer scope, usable as a subquery.
+func (e *Enforcer) userRoleIDs(userID uint) *gorm.DB {
+	return active(e.db.Model(&models.UserRoles{}).Select("role_id").
+		Where("user_id = ?", userID).
+		Where("scope IN ?", e.scopes()), "user_roles")
+}
+
+// findRolesById find all the given roles with a single query.
+func (e *Enforcer) findRolesById(ids []uint) ([]models.Role, error) {
+	roles := []models.Role{}
+	if len(ids) == 0 {
+		return roles, nil
+	}
+	res := e.db.Where("id IN ?", ids).Order("id").Find(&roles)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return roles, nil
+}
@@ -0,0 +1,48 @@
+package grole
+
+import (
+	"context"
+)
+
+type scopeKey struct{}
+
+// Return a copy of the context carrying the given scope (tenant, team or
+// organization id), WithContext picks it up.
+// @param context.Context, string
+// @return context.Context
+func ContextWithScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// Return the scope carried by the context.
+// @param context.Context
+// @return string, bool
+func ScopeFromContext(ctx context.Context) (string, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(string)
+	return scope, ok
+}
+
+// Return a copy of the enforcer working in the given scope. Roles are
+// assigned, revoked and checked in that scope only, assignments and roles
+// without a scope are global and apply to every scope.
+// @param string
+// @return *Enforcer
+func (e *Enforcer) WithScope(scope string) *Enforcer {
+	clone := *e
+	clone.scope = scope
+	return &clone
+}
+
+// Return the scope of the enforcer, empty when it isn't scoped.
+// @return string
+func (e *Enforcer) Scope() string {
+	return e.scope
+}
+
+// scopes return the scopes visible from the enforcer scope.
+func (e *Enforcer) scopes() []string {
+	if e.scope == "" {
+		return []string{""}
+	}
+	return []string{e.scope, ""}
+}
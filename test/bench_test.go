@@ -0,0 +1,104 @@
+package test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+// countQueries open a new connection with the dialector of db which counts
+// every query and raw statement it sends, subqueries are built in dry run
+// mode and aren't counted.
+func countQueries(b *testing.B) (*gorm.DB, *int64) {
+	var queries int64
+	counted, err := gorm.Open(db.Dialector, &gorm.Config{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	count := func(tx *gorm.DB) {
+		if !tx.DryRun {
+			atomic.AddInt64(&queries, 1)
+		}
+	}
+	counted.Callback().Query().After("gorm:query").Register("test:count_queries", count)
+	counted.Callback().Row().After("gorm:row").Register("test:count_queries", count)
+	counted.Callback().Raw().After("gorm:raw").Register("test:count_queries", count)
+	return counted, &queries
+}
+
+// benchmarkCheck assign roles with several permissions to a user and report
+// the number of queries each call of check runs.
+func benchmarkCheck(b *testing.B, check func(e *grole.Enforcer, userID uint) error) {
+	counted, queries := countQueries(b)
+	e := grole.NewEnforcer(grole.Options{DB: counted})
+
+	var roleNames []string
+	var roleIds []uint
+	for _, name := range []string{"bench-writer", "bench-editor", "bench-admin"} {
+		role, err := e.FindOrCreateRole(models.Role{Name: name, Description: "bench"})
+		if err != nil {
+			b.Fatal(err)
+		}
+		roleNames = append(roleNames, name)
+		roleIds = append(roleIds, role.ID)
+	}
+	var permissionIds []uint
+	for _, name := range []string{"bench.read", "bench.write", "bench.publish"} {
+		permission, err := e.FindOrCreatePermission(models.Permission{Name: name, Description: "bench"})
+		if err != nil {
+			b.Fatal(err)
+		}
+		permissionIds = append(permissionIds, permission.ID)
+		for _, roleId := range roleIds {
+			if _, err := e.AssignPermissionsFromRole(roleId, name); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	if _, err := e.AssignRoles(200, roleNames...); err != nil {
+		b.Fatal(err)
+	}
+
+	atomic.StoreInt64(queries, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := check(e, 200); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(atomic.LoadInt64(queries))/float64(b.N), "queries/op")
+
+	e.RemoveAllRoleFromUser(200)
+	for _, roleId := range roleIds {
+		e.RemoveAllPermissionFromRole(roleId)
+		e.DeleteRole(roleId)
+	}
+	for _, permissionId := range permissionIds {
+		e.DeletePermission(permissionId)
+	}
+}
+
+func BenchmarkHasAnyPermissions(b *testing.B) {
+	benchmarkCheck(b, func(e *grole.Enforcer, userID uint) error {
+		_, err := e.HasAnyPermissions(userID, "bench.publish")
+		return err
+	})
+}
+
+func BenchmarkHasAnyRole(b *testing.B) {
+	benchmarkCheck(b, func(e *grole.Enforcer, userID uint) error {
+		_, err := e.HasAnyRole(userID, "bench-admin")
+		return err
+	})
+}
+
+func BenchmarkGetAllPermissions(b *testing.B) {
+	benchmarkCheck(b, func(e *grole.Enforcer, userID uint) error {
+		_, err := e.GetAllPermissions(userID)
+		return err
+	})
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	cache := grole.NewMemoryCache(2)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("a"), time.Minute))
+	require.NoError(t, cache.Set(ctx, "b", []byte("b"), time.Minute))
+	_, ok, _ := cache.Get(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, cache.Set(ctx, "c", []byte("c"), time.Minute))
+
+	// b is the least recently used entry.
+	_, ok, _ = cache.Get(ctx, "b")
+	require.False(t, ok)
+	value, ok, _ := cache.Get(ctx, "a")
+	require.True(t, ok)
+	require.Equal(t, []byte("a"), value)
+	_, ok, _ = cache.Get(ctx, "c")
+	require.True(t, ok)
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := grole.NewMemoryCache(0)
+
+	require.NoError(t, cache.Set(ctx, "user:1:roles", []byte("1"), 20*time.Millisecond))
+	require.NoError(t, cache.Set(ctx, "user:2:roles", []byte("2"), time.Minute))
+	_, ok, _ := cache.Get(ctx, "user:1:roles")
+	require.True(t, ok)
+
+	time.Sleep(40 * time.Millisecond)
+	_, ok, _ = cache.Get(ctx, "user:1:roles")
+	require.False(t, ok)
+	_, ok, _ = cache.Get(ctx, "user:2:roles")
+	require.True(t, ok)
+
+	require.NoError(t, cache.DeletePrefix(ctx, "user:2:"))
+	_, ok, _ = cache.Get(ctx, "user:2:roles")
+	require.False(t, ok)
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	enforcer := grole.NewEnforcer(grole.Options{DB: db, CacheTTL: time.Minute})
+	allowed := func() bool {
+		allowed, err := enforcer.HasAnyPermissions(140, "cache.read")
+		require.NoError(t, err)
+		return allowed
+	}
+
+	permission, err := enforcer.FindOrCreatePermission(models.Permission{Name: "cache.read"})
+	require.NoError(t, err)
+	role, err := enforcer.FindOrCreateRole(models.Role{Name: "cache-reader"})
+	require.NoError(t, err)
+	_, err = enforcer.AssignPermissionsFromRole(role.ID, "cache.read")
+	require.NoError(t, err)
+	member, err := enforcer.FindOrCreateRole(models.Role{Name: "cache-member"})
+	require.NoError(t, err)
+	_, err = enforcer.AddParentRole(member.ID, "cache-reader")
+	require.NoError(t, err)
+	require.False(t, allowed())
+
+	_, err = enforcer.AssignRoles(140, "cache-member")
+	require.NoError(t, err)
+	require.True(t, allowed())
+
+	_, err = enforcer.SyncPermissionsFromRole(role.ID)
+	require.NoError(t, err)
+	require.False(t, allowed())
+
+	_, err = enforcer.SyncPermissionsFromRole(role.ID, "cache.read")
+	require.NoError(t, err)
+	require.True(t, allowed())
+
+	_, err = enforcer.UpdatePermission(permission.ID, models.Permission{Name: "cache.view"})
+	require.NoError(t, err)
+	require.False(t, allowed())
+
+	_, err = enforcer.UpdatePermission(permission.ID, models.Permission{Name: "cache.read"})
+	require.NoError(t, err)
+	require.True(t, allowed())
+
+	// the role inherited by the role of the user.
+	_, err = enforcer.DeleteRole(role.ID)
+	require.NoError(t, err)
+	require.False(t, allowed())
+
+	enforcer.RemoveAllRoleFromUser(140)
+	enforcer.DeleteRole(member.ID)
+	enforcer.DeletePermission(permission.ID)
+}
+
+// Changes made behind the enforcer's back stay cached until ForgetUser or
+// FlushCache drop them.
+func TestForgetUser(t *testing.T) {
+	enforcer := grole.NewEnforcer(grole.Options{DB: db, CacheTTL: time.Minute})
+	role, err := enforcer.FindOrCreateRole(models.Role{Name: "cache-forgotten"})
+	require.NoError(t, err)
+	defer enforcer.DeleteRole(role.ID)
+
+	for _, forget := range []func(){
+		func() { enforcer.ForgetUser(141) },
+		enforcer.FlushCache,
+	} {
+		_, err = enforcer.AssignRoles(141, "cache-forgotten")
+		require.NoError(t, err)
+		has, err := enforcer.HasAnyRole(141, "cache-forgotten")
+		require.NoError(t, err)
+		require.True(t, has)
+
+		require.NoError(t, db.Where("user_id = ?", 141).Delete(&models.UserRoles{}).Error)
+		has, err = enforcer.HasAnyRole(141, "cache-forgotten")
+		require.NoError(t, err)
+		require.True(t, has)
+
+		forget()
+		has, err = enforcer.HasAnyRole(141, "cache-forgotten")
+		require.NoError(t, err)
+		require.False(t, has)
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Enforcers on separate schemas share nothing, and creating one leaves the
+// default enforcer alone.
+func TestIndependentEnforcers(t *testing.T) {
+	require.NoError(t, db.Exec("CREATE SCHEMA IF NOT EXISTS grole_tenant").Error)
+	tenantDB, err := gorm.Open(postgres.Open(dsn+" search_path=grole_tenant"), &gorm.Config{})
+	require.NoError(t, err)
+
+	defaultEnforcer := grole.New(grole.Options{DB: db})
+	tenant := grole.NewEnforcer(grole.Options{DB: tenantDB, CacheTTL: time.Minute})
+	require.Same(t, defaultEnforcer, grole.Default())
+
+	role, err := tenant.FindOrCreateRole(models.Role{Name: "tenant-only"})
+	require.NoError(t, err)
+	defer tenant.DeleteRole(role.ID)
+	_, err = tenant.AssignRoles(150, "tenant-only")
+	require.NoError(t, err)
+	defer tenant.RemoveAllRoleFromUser(150)
+
+	has, err := tenant.HasAnyRole(150, "tenant-only")
+	require.NoError(t, err)
+	require.True(t, has)
+
+	_, err = grole.FindRoleByName("tenant-only")
+	require.ErrorIs(t, err, grole.ErrRoleNotFound)
+	has, err = grole.HasAnyRole(150, "tenant-only")
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func TestCancelledContext(t *testing.T) {
+	enforcer := grole.NewEnforcer(grole.Options{DB: db})
+	role, err := enforcer.FindOrCreateRole(models.Role{Name: "cancelled"})
+	require.NoError(t, err)
+	defer enforcer.DeleteRole(role.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := enforcer.WithContext(ctx)
+
+	_, err = cancelled.HasAnyPermissions(151, "articles.read")
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = cancelled.GetAllPermissions(151)
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = cancelled.SyncRolesFromUser(151, "cancelled")
+	require.ErrorIs(t, err, context.Canceled)
+
+	roles, err := enforcer.GetRoleNames(151)
+	require.NoError(t, err)
+	require.Empty(t, roles)
+}
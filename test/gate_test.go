@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/gate"
+	"github.com/mousav1/grole/models"
+	"github.com/stretchr/testify/require"
+)
+
+type post struct {
+	AuthorID uint
+}
+
+type postPolicy struct{}
+
+func (postPolicy) Update(ctx context.Context, user gate.User, article *post) (bool, error) {
+	return article.AuthorID == user.ID, nil
+}
+
+func TestGateAuthorize(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	moderate, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "posts.moderate", Description: "test"})
+	role, errRole := grole.FindOrCreateRole(models.Role{Name: "moderator", Description: "test"})
+	require.NoError(t, errPermission)
+	require.NoError(t, errRole)
+	_, errAssignPermission := grole.AssignPermissionsFromRole(role.ID, "posts.moderate")
+	_, errAssignRole := grole.AssignRoles(115, "moderator")
+	require.NoError(t, errAssignPermission)
+	require.NoError(t, errAssignRole)
+
+	gate.Define("update-post", func(ctx context.Context, user gate.User, args ...interface{}) (bool, error) {
+		if args[0].(*post).AuthorID == user.ID {
+			return true, nil
+		}
+		return user.HasAnyPermissions("posts.moderate")
+	})
+
+	ctx := context.Background()
+	article := &post{AuthorID: 116}
+	require.NoError(t, gate.Authorize(ctx, 116, "update-post", article))
+	require.NoError(t, gate.Authorize(ctx, 115, "update-post", article))
+
+	errAuthorize := gate.Authorize(ctx, 117, "update-post", article)
+	var authErr *gate.AuthorizationError
+	require.True(t, errors.As(errAuthorize, &authErr))
+	require.Equal(t, "update-post", authErr.Ability)
+
+	_, errUndefined := gate.Allows(ctx, 115, "delete-post", article)
+	require.ErrorIs(t, errUndefined, gate.ErrAbilityNotDefined)
+
+	grole.RemoveAllRoleFromUser(115)
+	grole.RemoveAllPermissionFromRole(role.ID)
+	grole.DeleteRole(role.ID)
+	grole.DeletePermission(moderate.ID)
+}
+
+func TestGatePolicy(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	publish, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "post.publish", Description: "test"})
+	require.NoError(t, errPermission)
+	_, errGive := grole.GivePermissionToUser(118, "post.publish")
+	require.NoError(t, errGive)
+
+	policyGate := gate.NewGate(gate.Options{})
+	policyGate.Policy(&post{}, postPolicy{})
+
+	ctx := context.Background()
+	article := &post{AuthorID: 119}
+	allowed, errAllows := policyGate.Allows(ctx, 119, "update", article)
+	require.NoError(t, errAllows)
+	require.True(t, allowed)
+	allowed, errAllows = policyGate.Allows(ctx, 118, "update", article)
+	require.NoError(t, errAllows)
+	require.False(t, allowed)
+
+	// without policy method, the post.publish permission decides
+	allowed, errAllows = policyGate.Allows(ctx, 118, "publish", article)
+	require.NoError(t, errAllows)
+	require.True(t, allowed)
+	allowed, errAllows = policyGate.Allows(ctx, 119, "publish", article)
+	require.NoError(t, errAllows)
+	require.False(t, allowed)
+
+	grole.RevokePermissionFromUser(118, "post.publish")
+	grole.DeletePermission(publish.ID)
+}
+
+type invalidPolicy struct{}
+
+func (invalidPolicy) Update(user gate.User, article *post) bool {
+	return true
+}
+
+func TestGatePolicySignature(t *testing.T) {
+	require.Panics(t, func() {
+		gate.NewGate(gate.Options{}).Policy(&post{}, invalidPolicy{})
+	})
+}
+
+func TestGatePolicySeparator(t *testing.T) {
+	e := grole.NewEnforcer(grole.Options{
+		DB:                  db,
+		PermissionSeparator: ":",
+	})
+
+	all, errPermission := e.FindOrCreatePermission(models.Permission{Name: "post:*", Description: "test"})
+	require.NoError(t, errPermission)
+	_, errGive := e.GivePermissionToUser(121, "post:*")
+	require.NoError(t, errGive)
+
+	policyGate := gate.NewGate(gate.Options{Enforcer: e})
+	policyGate.Policy(&post{}, postPolicy{})
+	allowed, errAllows := policyGate.Allows(context.Background(), 121, "publish", &post{})
+	require.NoError(t, errAllows)
+	require.True(t, allowed)
+
+	e.RevokePermissionFromUser(121, "post:*")
+	e.DeletePermission(all.ID)
+}
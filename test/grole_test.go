@@ -1,7 +1,14 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mousav1/grole"
 	"github.com/mousav1/grole/models"
@@ -81,5 +88,670 @@ func TestDeletePermission(t *testing.T) {
 	grole.DeletePermission(permission.ID)
 
 	_, errNewPermissionDelete := grole.FindPermissionById(permission.ID)
-	require.EqualError(t, errNewPermissionDelete, "RECORD NOT FOUND")
+	require.ErrorIs(t, errNewPermissionDelete, grole.ErrPermissionNotFound)
+}
+
+func TestFindRoleByNameNotFound(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	_, err := grole.FindRoleByName("role-that-does-not-exist")
+
+	require.ErrorIs(t, err, grole.ErrRoleNotFound)
+
+	var groleErr *grole.Error
+	require.ErrorAs(t, err, &groleErr)
+	require.Equal(t, grole.EntityRole, groleErr.Entity)
+	require.Equal(t, "role-that-does-not-exist", groleErr.Key)
+}
+
+func TestSyncRolesFromUserRollback(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	role, errCreateRole := grole.FindOrCreateRole(models.Role{
+		Name:        "writer",
+		Description: "test",
+	})
+	require.NoError(t, errCreateRole)
+
+	_, errAssign := grole.AssignRoles(100, "writer")
+	require.NoError(t, errAssign)
+
+	_, errSync := grole.SyncRolesFromUser(100, "writer", "role-that-does-not-exist")
+	require.ErrorIs(t, errSync, grole.ErrRoleNotFound)
+
+	names, errNames := grole.GetRoleNames(100)
+	require.NoError(t, errNames)
+	require.Equal(t, []string{"writer"}, names)
+
+	grole.RemoveAllRoleFromUser(100)
+	grole.DeleteRole(role.ID)
+}
+
+func TestNestedTransactionRollback(t *testing.T) {
+	e := grole.New(grole.Options{
+		DB: db,
+	})
+
+	role, errCreateRole := grole.FindOrCreateRole(models.Role{
+		Name:        "nested",
+		Description: "test",
+	})
+	require.NoError(t, errCreateRole)
+
+	var events []grole.Event
+	e.BeforeCommit(func(tx *grole.Tx, event grole.Event) error {
+		events = append(events, event)
+		return nil
+	})
+
+	failed := errors.New("failed")
+	since := time.Now()
+	errTx := grole.Transaction(func(tx *grole.Tx) error {
+		errNested := tx.Transaction(func(inner *grole.Tx) error {
+			_, errAssign := inner.AssignRoles(120, "nested")
+			require.NoError(t, errAssign)
+			return failed
+		})
+		require.ErrorIs(t, errNested, failed)
+		return nil
+	})
+	require.NoError(t, errTx)
+
+	hasRole, errHas := grole.HasRole(120, role.ID)
+	require.ErrorIs(t, errHas, grole.ErrRoleNotAssigned)
+	require.False(t, hasRole)
+	require.Empty(t, events)
+	entries, errAudit := grole.GetUserAuditLog(120, since, time.Time{})
+	require.NoError(t, errAudit)
+	require.Empty(t, entries)
+
+	grole.New(grole.Options{DB: db})
+	grole.DeleteRole(role.ID)
+}
+
+func TestGivePermissionToUser(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	permission, errCreatePermission := grole.FindOrCreatePermission(models.Permission{
+		Name:        "publish-articles",
+		Description: "test",
+	})
+	require.NoError(t, errCreatePermission)
+
+	_, errGive := grole.GivePermissionToUser(101, "publish-articles")
+	require.NoError(t, errGive)
+
+	ok, errHas := grole.HasAnyPermissions(101, "publish-articles")
+	require.NoError(t, errHas)
+	require.True(t, ok)
+
+	_, errRevoke := grole.RevokePermissionFromUser(101, "publish-articles")
+	require.NoError(t, errRevoke)
+
+	ok, errHas = grole.HasAnyPermissions(101, "publish-articles")
+	require.NoError(t, errHas)
+	require.False(t, ok)
+
+	grole.DeletePermission(permission.ID)
+}
+
+func TestAddParentRole(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	viewer, errViewer := grole.FindOrCreateRole(models.Role{Name: "viewer", Description: "test"})
+	editor, errEditor := grole.FindOrCreateRole(models.Role{Name: "editor", Description: "test"})
+	require.NoError(t, errViewer)
+	require.NoError(t, errEditor)
+
+	_, errAdd := grole.AddParentRole(editor.ID, "viewer")
+	require.NoError(t, errAdd)
+
+	_, errCycle := grole.AddParentRole(viewer.ID, "editor")
+	require.ErrorIs(t, errCycle, grole.ErrRoleHierarchyCycle)
+
+	_, errAssign := grole.AssignRoles(102, "editor")
+	require.NoError(t, errAssign)
+
+	ok, errHas := grole.HasAnyRole(102, "viewer")
+	require.NoError(t, errHas)
+	require.True(t, ok)
+
+	grole.RemoveAllRoleFromUser(102)
+	grole.DeleteRole(editor.ID)
+	grole.DeleteRole(viewer.ID)
+}
+
+func TestAssignRolesWithScope(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	role, errCreateRole := grole.FindOrCreateRole(models.Role{
+		Name:        "owner",
+		Description: "test",
+	})
+	require.NoError(t, errCreateRole)
+
+	_, errAssign := grole.WithScope("org-a").AssignRoles(103, "owner")
+	require.NoError(t, errAssign)
+
+	ok, errHas := grole.WithScope("org-a").HasAnyRole(103, "owner")
+	require.NoError(t, errHas)
+	require.True(t, ok)
+
+	ok, errHas = grole.WithScope("org-b").HasAnyRole(103, "owner")
+	require.NoError(t, errHas)
+	require.False(t, ok)
+
+	grole.WithScope("org-a").RemoveAllRoleFromUser(103)
+	grole.DeleteRole(role.ID)
+}
+
+func TestHasAllRole(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	writer, errWriter := grole.FindOrCreateRole(models.Role{Name: "writer", Description: "test"})
+	reviewer, errReviewer := grole.FindOrCreateRole(models.Role{Name: "reviewer", Description: "test"})
+	require.NoError(t, errWriter)
+	require.NoError(t, errReviewer)
+
+	_, errAssign := grole.AssignRoles(104, "writer", "reviewer")
+	require.NoError(t, errAssign)
+
+	ok, errHas := grole.HasAllRole(104, "reviewer", "writer")
+	require.NoError(t, errHas)
+	require.True(t, ok)
+
+	ok, errHas = grole.HasAllRole(104, "writer")
+	require.NoError(t, errHas)
+	require.True(t, ok)
+
+	result, errCheck := grole.CheckRoles(104, "writer", "admin")
+	require.NoError(t, errCheck)
+	require.False(t, result.Allowed())
+	require.Equal(t, []string{"writer"}, result.Satisfied)
+	require.Equal(t, []string{"admin"}, result.Missing)
+
+	grole.RemoveAllRoleFromUser(104)
+	grole.DeleteRole(writer.ID)
+	grole.DeleteRole(reviewer.ID)
+}
+
+func TestAuditLog(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	auditor, errRole := grole.FindOrCreateRole(models.Role{Name: "auditor", Description: "test"})
+	require.NoError(t, errRole)
+
+	since := time.Now()
+	_, errAssign := grole.WithActor("alice").AssignRoles(107, "auditor")
+	require.NoError(t, errAssign)
+
+	entries, errAudit := grole.GetUserAuditLog(107, since, time.Time{})
+	require.NoError(t, errAudit)
+	require.Len(t, entries, 1)
+	require.Equal(t, "alice", entries[0].Actor)
+	require.Equal(t, string(grole.ActionRoleAssigned), entries[0].Action)
+	require.Equal(t, auditor.ID, entries[0].RoleID)
+
+	grole.RemoveAllRoleFromUser(107)
+	grole.DeleteRole(auditor.ID)
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	reviewer, errRole := grole.FindOrCreateRole(models.Role{Name: "chain-reviewer", Description: "test"})
+	require.NoError(t, errRole)
+	since := time.Now()
+	_, errAssign := grole.WithActor("chain-auditor").AssignRoles(108, "chain-reviewer")
+	require.NoError(t, errAssign)
+	_, errRemove := grole.RemoveAllRoleFromUser(108)
+	require.NoError(t, errRemove)
+	require.NoError(t, grole.VerifyAuditChain())
+
+	entries, errAudit := grole.GetUserAuditLog(108, since, time.Time{})
+	require.NoError(t, errAudit)
+	require.Len(t, entries, 2)
+
+	// tamper with the log in a transaction rolled back afterwards.
+	rollback := errors.New("rollback")
+	for reason, tamper := range map[error]func(tx *grole.Tx) error{
+		grole.ErrAuditEntryModified: func(tx *grole.Tx) error {
+			return tx.DB().Model(&models.AuditLog{}).Where("id = ?", entries[0].ID).Update("actor", "mallory").Error
+		},
+		grole.ErrAuditChainGap: func(tx *grole.Tx) error {
+			return tx.DB().Delete(&models.AuditLog{}, entries[0].ID).Error
+		},
+	} {
+		errTx := grole.Transaction(func(tx *grole.Tx) error {
+			require.NoError(t, tamper(tx))
+			require.ErrorIs(t, tx.VerifyAuditChain(), reason)
+			return rollback
+		})
+		require.ErrorIs(t, errTx, rollback)
+	}
+	require.NoError(t, grole.VerifyAuditChain())
+
+	publicKey, privateKey, errKey := ed25519.GenerateKey(nil)
+	require.NoError(t, errKey)
+	var export bytes.Buffer
+	require.NoError(t, grole.ExportAuditLog(&export, privateKey, 1))
+	require.NoError(t, grole.VerifyAuditExport(bytes.NewReader(export.Bytes()), publicKey))
+
+	modified := bytes.Replace(export.Bytes(), []byte(`"Actor":"chain-auditor"`), []byte(`"Actor":"chain-auditoR"`), 1)
+	require.ErrorIs(t, grole.VerifyAuditExport(bytes.NewReader(modified), publicKey), grole.ErrAuditEntryModified)
+
+	otherKey, _, errKey := ed25519.GenerateKey(nil)
+	require.NoError(t, errKey)
+	require.ErrorIs(t, grole.VerifyAuditExport(bytes.NewReader(export.Bytes()), otherKey), grole.ErrAuditCheckpointInvalid)
+
+	// the last entry, its checkpoint and the final checkpoint are cut off.
+	lines := bytes.SplitAfter(export.Bytes(), []byte("\n"))
+	truncated := bytes.Join(lines[:len(lines)-4], nil)
+	require.ErrorIs(t, grole.VerifyAuditExport(bytes.NewReader(truncated), publicKey), grole.ErrAuditCheckpointInvalid)
+
+	grole.DeleteRole(reviewer.ID)
+}
+
+func TestEventHooks(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	vetoed := errors.New("vetoed")
+	grole.BeforeCommit(func(tx *grole.Tx, event grole.Event) error {
+		if e, ok := event.(*grole.RoleAssigned); ok && e.UserID == 110 {
+			return vetoed
+		}
+		return nil
+	})
+	assigned := make(chan *grole.RoleAssigned, 1)
+	grole.AfterCommit(func(event grole.Event) {
+		if e, ok := event.(*grole.RoleAssigned); ok {
+			assigned <- e
+		}
+	})
+
+	observed, errRole := grole.FindOrCreateRole(models.Role{Name: "observed", Description: "test"})
+	require.NoError(t, errRole)
+
+	_, errAssign := grole.AssignRoles(110, "observed")
+	require.ErrorIs(t, errAssign, vetoed)
+	hasRole, errHas := grole.HasRole(110, observed.ID)
+	require.ErrorIs(t, errHas, grole.ErrRoleNotAssigned)
+	require.False(t, hasRole)
+
+	_, errAssign = grole.WithActor("alice").AssignRoles(109, "observed")
+	require.NoError(t, errAssign)
+	select {
+	case e := <-assigned:
+		require.Equal(t, uint(109), e.UserID)
+		require.Equal(t, "observed", e.Role.Name)
+		require.Equal(t, "alice", e.Info().Actor)
+	case <-time.After(time.Second):
+		t.Fatal("the after commit hook wasn't called")
+	}
+
+	grole.RemoveAllRoleFromUser(109)
+	grole.DeleteRole(observed.ID)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	e := grole.New(grole.Options{
+		DB:     db,
+		Outbox: true,
+	})
+	defer grole.New(grole.Options{DB: db})
+
+	relayed, errRole := e.FindOrCreateRole(models.Role{Name: "relayed", Description: "test"})
+	require.NoError(t, errRole)
+
+	var events []grole.Event
+	relay := grole.NewRelay(grole.RelayOptions{
+		Sink: grole.SinkFunc(func(ctx context.Context, message grole.OutboxMessage) error {
+			event, err := message.Decode()
+			events = append(events, event)
+			return err
+		}),
+	})
+	drainOutbox(t)
+
+	_, errAssign := grole.AssignRoles(111, "relayed")
+	require.NoError(t, errAssign)
+	_, errDeliver := relay.Deliver(context.Background())
+	require.NoError(t, errDeliver)
+	require.Len(t, events, 1)
+	assigned, ok := events[0].(*grole.RoleAssigned)
+	require.True(t, ok)
+	require.Equal(t, uint(111), assigned.UserID)
+	require.Equal(t, relayed.ID, assigned.Role.ID)
+
+	grole.RemoveAllRoleFromUser(111)
+	grole.DeleteRole(relayed.ID)
+	grole.PurgeOutbox(time.Now().Add(time.Minute))
+}
+
+func TestOutboxDeadLetter(t *testing.T) {
+	e := grole.New(grole.Options{
+		DB:     db,
+		Outbox: true,
+	})
+	defer grole.New(grole.Options{DB: db})
+
+	lettered, errRole := e.FindOrCreateRole(models.Role{Name: "dead-lettered", Description: "test"})
+	require.NoError(t, errRole)
+	drainOutbox(t)
+
+	down := errors.New("sink down")
+	calls := 0
+	failing := grole.SinkFunc(func(ctx context.Context, message grole.OutboxMessage) error {
+		calls++
+		return down
+	})
+
+	_, errAssign := grole.AssignRoles(123, "dead-lettered")
+	require.NoError(t, errAssign)
+	relay := grole.NewRelay(grole.RelayOptions{Sink: failing, MaxAttempts: 1})
+	count, errDeliver := relay.Deliver(context.Background())
+	require.NoError(t, errDeliver)
+	require.Equal(t, 1, count)
+
+	dead, errOutbox := grole.GetOutbox(grole.OutboxDead, 0)
+	require.NoError(t, errOutbox)
+	require.Len(t, dead, 1)
+	require.Equal(t, 1, dead[0].Attempts)
+	require.Equal(t, down.Error(), dead[0].LastError)
+
+	// a dead message isn't claimed anymore, until it is requeued.
+	count, errDeliver = relay.Deliver(context.Background())
+	require.NoError(t, errDeliver)
+	require.Zero(t, count)
+	require.Equal(t, 1, calls)
+
+	require.NoError(t, grole.RequeueOutbox(dead[0].ID))
+	messages := make(chan grole.OutboxMessage, 1)
+	_, errDeliver = grole.NewRelay(grole.RelayOptions{Sink: grole.ChannelSink(messages)}).Deliver(context.Background())
+	require.NoError(t, errDeliver)
+	message := <-messages
+	require.Equal(t, dead[0].ID, message.ID)
+	require.Equal(t, 1, message.Attempts)
+
+	// a failed message is tried again after the backoff.
+	_, errRemove := grole.RemoveAllRoleFromUser(123)
+	require.NoError(t, errRemove)
+	relay = grole.NewRelay(grole.RelayOptions{
+		Sink:        failing,
+		MaxAttempts: 2,
+		Backoff: func(attempts int) time.Duration {
+			return time.Hour
+		},
+	})
+	_, errDeliver = relay.Deliver(context.Background())
+	require.NoError(t, errDeliver)
+	pending, errOutbox := grole.GetOutbox(grole.OutboxPending, 0)
+	require.NoError(t, errOutbox)
+	require.Len(t, pending, 1)
+	require.Equal(t, 1, pending[0].Attempts)
+	require.True(t, pending[0].NextAttemptAt.After(time.Now().Add(50*time.Minute)))
+	count, errDeliver = relay.Deliver(context.Background())
+	require.NoError(t, errDeliver)
+	require.Zero(t, count)
+	require.Equal(t, 2, calls)
+
+	db.Delete(&models.Outbox{}, pending[0].ID)
+	grole.DeleteRole(lettered.ID)
+	drainOutbox(t)
+	grole.PurgeOutbox(time.Now().Add(time.Minute))
+}
+
+func TestWebhookSink(t *testing.T) {
+	status := http.StatusNoContent
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := grole.NewWebhookSink(server.URL)
+	message := grole.OutboxMessage{ID: 42, Action: grole.ActionRoleAssigned, Event: []byte(`{}`)}
+	require.NoError(t, sink.Deliver(context.Background(), message))
+	status = http.StatusInternalServerError
+	require.Error(t, sink.Deliver(context.Background(), message))
+	require.Equal(t, []string{"42", "42"}, keys)
+}
+
+// drainOutbox deliver the due messages of the previous tests.
+func drainOutbox(t *testing.T) {
+	relay := grole.NewRelay(grole.RelayOptions{
+		Sink: grole.SinkFunc(func(ctx context.Context, message grole.OutboxMessage) error {
+			return nil
+		}),
+	})
+	for {
+		count, errDeliver := relay.Deliver(context.Background())
+		require.NoError(t, errDeliver)
+		if count == 0 {
+			break
+		}
+	}
+}
+
+func TestAssignRolesUntil(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	oncall, errRole := grole.FindOrCreateRole(models.Role{Name: "on-call", Description: "test"})
+	require.NoError(t, errRole)
+
+	_, errAssign := grole.AssignRolesUntil(112, time.Now().Add(-time.Minute), "on-call")
+	require.NoError(t, errAssign)
+	_, errHas := grole.HasRole(112, oncall.ID)
+	require.ErrorIs(t, errHas, grole.ErrRoleNotAssigned)
+
+	_, errAssign = grole.AssignRolesUntil(113, time.Now().Add(time.Hour), "on-call")
+	require.NoError(t, errAssign)
+	hasRole, errHas := grole.HasRole(113, oncall.ID)
+	require.NoError(t, errHas)
+	require.True(t, hasRole)
+
+	purged, errPurge := grole.PurgeExpiredRoles()
+	require.NoError(t, errPurge)
+	require.GreaterOrEqual(t, purged, int64(1))
+
+	grole.RemoveAllRoleFromUser(113)
+	grole.DeleteRole(oncall.ID)
+}
+
+func TestAssignPermissionsFromRoleUntil(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	support, errRole := grole.FindOrCreateRole(models.Role{Name: "support", Description: "test"})
+	require.NoError(t, errRole)
+	_, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "billing.refund", Description: "test"})
+	require.NoError(t, errPermission)
+
+	_, errAssign := grole.AssignPermissionsFromRoleUntil(support.ID, time.Now().Add(-time.Minute), "billing.refund")
+	require.NoError(t, errAssign)
+	permission, errHas := grole.HasPermissionTo(support.ID, "billing.refund")
+	require.NoError(t, errHas)
+	require.Zero(t, permission.ID)
+
+	_, errAssign = grole.AssignPermissionsFromRoleUntil(support.ID, time.Now().Add(time.Hour), "billing.refund")
+	require.NoError(t, errAssign)
+	permission, errHas = grole.HasPermissionTo(support.ID, "billing.refund")
+	require.NoError(t, errHas)
+	require.NotZero(t, permission.ID)
+
+	grole.DeleteRole(support.ID)
+	grole.DeletePermission(permission.ID)
+}
+
+func TestSyncPermissionsFromRoleExpired(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	support, errRole := grole.FindOrCreateRole(models.Role{Name: "support", Description: "test"})
+	require.NoError(t, errRole)
+	_, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "billing.refund", Description: "test"})
+	require.NoError(t, errPermission)
+
+	_, errAssign := grole.AssignPermissionsFromRoleUntil(support.ID, time.Now().Add(-time.Minute), "billing.refund")
+	require.NoError(t, errAssign)
+	_, errSync := grole.SyncPermissionsFromRole(support.ID, "billing.refund")
+	require.NoError(t, errSync)
+	permission, errHas := grole.HasPermissionTo(support.ID, "billing.refund")
+	require.NoError(t, errHas)
+	require.NotZero(t, permission.ID)
+
+	grole.DeleteRole(support.ID)
+	grole.DeletePermission(permission.ID)
+}
+
+func TestCanOn(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	edit, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "edit", Description: "test"})
+	require.NoError(t, errPermission)
+
+	_, errGrant := grole.GrantOn(114, "edit", "article", 1337)
+	require.NoError(t, errGrant)
+
+	can, errCan := grole.CanOn(114, "edit", "article", 1337)
+	require.NoError(t, errCan)
+	require.True(t, can)
+	can, errCan = grole.CanOn(114, "edit", "article", 1338)
+	require.NoError(t, errCan)
+	require.False(t, can)
+
+	ids, errIds := grole.GetResourceIDs(114, "edit", "article")
+	require.NoError(t, errIds)
+	require.Equal(t, []uint{1337}, ids)
+
+	_, errRevoke := grole.RevokeOn(114, "edit", "article", 1337)
+	require.NoError(t, errRevoke)
+	grole.DeletePermission(edit.ID)
+}
+
+func TestCanOnZeroUser(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	editor, errRole := grole.FindOrCreateRole(models.Role{Name: "article-editor", Description: "test"})
+	require.NoError(t, errRole)
+	edit, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "edit", Description: "test"})
+	require.NoError(t, errPermission)
+	_, errGrant := grole.GrantRoleOn(editor.ID, "edit", "article", 1339)
+	require.NoError(t, errGrant)
+
+	can, errCan := grole.CanOn(0, "edit", "article", 1339)
+	require.NoError(t, errCan)
+	require.False(t, can)
+	ids, errIds := grole.GetResourceIDs(0, "edit", "article")
+	require.NoError(t, errIds)
+	require.Empty(t, ids)
+
+	grole.DeleteRole(editor.ID)
+	grole.DeletePermission(edit.ID)
+}
+
+func TestSyncRolesFromUserExpired(t *testing.T) {
+	enforcer := grole.NewEnforcer(grole.Options{DB: db, CacheTTL: time.Minute})
+	var actions []grole.Action
+	enforcer.BeforeCommit(func(tx *grole.Tx, event grole.Event) error {
+		actions = append(actions, event.Info().Action)
+		return nil
+	})
+
+	returning, errRole := enforcer.FindOrCreateRole(models.Role{Name: "returning", Description: "test"})
+	require.NoError(t, errRole)
+	_, errAssign := enforcer.AssignRolesUntil(122, time.Now().Add(-time.Minute), "returning")
+	require.NoError(t, errAssign)
+	hasRole, errHas := enforcer.HasAnyRole(122, "returning")
+	require.NoError(t, errHas)
+	require.False(t, hasRole)
+
+	actions = nil
+	_, errSync := enforcer.SyncRolesFromUser(122, "returning")
+	require.NoError(t, errSync)
+	require.Equal(t, []grole.Action{grole.ActionRoleAssigned}, actions)
+	hasRole, errHas = enforcer.HasAnyRole(122, "returning")
+	require.NoError(t, errHas)
+	require.True(t, hasRole)
+
+	_, errAssign = enforcer.AssignRolesUntil(122, time.Now().Add(-time.Minute), "returning")
+	require.NoError(t, errAssign)
+	actions = nil
+	enforcer.RemoveAllRoleFromUser(122)
+	require.Empty(t, actions)
+
+	enforcer.DeleteRole(returning.ID)
+}
+
+func TestRoleListingExpired(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	lister, errRole := grole.FindOrCreateRole(models.Role{Name: "lister", Description: "test"})
+	require.NoError(t, errRole)
+	current, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "listing.current", Description: "test"})
+	require.NoError(t, errPermission)
+	expired, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "listing.expired", Description: "test"})
+	require.NoError(t, errPermission)
+	_, errAssign := grole.AssignPermissionsFromRole(lister.ID, "listing.current")
+	require.NoError(t, errAssign)
+	_, errAssign = grole.AssignPermissionsFromRoleUntil(lister.ID, time.Now().Add(-time.Minute), "listing.expired")
+	require.NoError(t, errAssign)
+
+	role, errFind := grole.FindRoleById(lister.ID)
+	require.NoError(t, errFind)
+	require.Len(t, role.Permissions, 1)
+	require.Equal(t, current.ID, role.Permissions[0].ID)
+
+	roles, errFind := grole.FindAllRole()
+	require.NoError(t, errFind)
+	for _, role := range roles {
+		if role.ID == lister.ID {
+			require.Len(t, role.Permissions, 1)
+			require.Equal(t, current.ID, role.Permissions[0].ID)
+		}
+	}
+
+	count, errCount := grole.CountPermissionFromRole(lister.ID)
+	require.NoError(t, errCount)
+	require.Equal(t, int64(1), count)
+
+	permission, errFind := grole.FindPermissionById(expired.ID)
+	require.NoError(t, errFind)
+	require.Empty(t, permission.Roles)
+	count, errCount = grole.CountRoleFromPermission(expired.ID)
+	require.NoError(t, errCount)
+	require.Zero(t, count)
+
+	grole.DeleteRole(lister.ID)
+	grole.DeletePermission(current.ID)
+	grole.DeletePermission(expired.ID)
 }
@@ -10,9 +10,10 @@ import (
 
 var db *gorm.DB
 
+const dsn = "host=localhost user=root password=secret dbname=grole port=5432 sslmode=disable TimeZone=Asia/Shanghai"
+
 func TestMain(m *testing.M) {
 
-	dsn := "host=localhost user=root password=secret dbname=grole port=5432 sslmode=disable TimeZone=Asia/Shanghai"
 	db, _ = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 
 	os.Exit(m.Run())
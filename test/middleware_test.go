@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/middleware"
+	"github.com/mousav1/grole/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirePermission(t *testing.T) {
+	grole.New(grole.Options{
+		DB: db,
+	})
+
+	permission, errPermission := grole.FindOrCreatePermission(models.Permission{Name: "invoices.create", Description: "test"})
+	role, errRole := grole.FindOrCreateRole(models.Role{Name: "accountant", Description: "test"})
+	require.NoError(t, errPermission)
+	require.NoError(t, errRole)
+
+	_, errAssignPermission := grole.AssignPermissionsFromRole(role.ID, "invoices.create")
+	_, errAssignRole := grole.AssignRoles(105, "accountant")
+	require.NoError(t, errAssignPermission)
+	require.NoError(t, errAssignRole)
+
+	handler := middleware.New(middleware.Options{
+		UserID: middleware.FromHeader("X-User-ID"),
+	}).RequirePermission("invoices.create")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for userID, status := range map[string]int{
+		"105": http.StatusNoContent,
+		"106": http.StatusForbidden,
+		"":    http.StatusUnauthorized,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/invoices", nil)
+		r.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, status, w.Code, userID)
+	}
+
+	grole.RemoveAllRoleFromUser(105)
+	grole.DeleteRole(role.ID)
+	grole.DeletePermission(permission.ID)
+}
+
+// requireUser only lets user 1 through and fails for user 2.
+func requireUser(e *grole.Enforcer, userID uint) (bool, error) {
+	if userID == 2 {
+		return false, errors.New("database is down")
+	}
+	return userID == 1, nil
+}
+
+// The framework adapters share this decision, their tests only cover how
+// they read the user and respond.
+func TestDecide(t *testing.T) {
+	m := middleware.NewMiddleware(middleware.Options{Enforcer: grole.New(grole.Options{DB: db})})
+
+	for userID, status := range map[uint]int{
+		1: http.StatusOK,
+		2: http.StatusInternalServerError,
+		3: http.StatusForbidden,
+	} {
+		err := m.Decide(context.Background(), userID, true, requireUser)
+		require.Equal(t, status, middleware.Status(err), userID)
+	}
+	err := m.Decide(context.Background(), 1, false, requireUser)
+	require.ErrorIs(t, err, middleware.ErrUnauthenticated)
+	require.Equal(t, http.StatusUnauthorized, middleware.Status(err))
+}
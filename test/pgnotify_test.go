@@ -0,0 +1,89 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mousav1/grole"
+	"github.com/mousav1/grole/models"
+	"github.com/mousav1/grole/pgnotify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgnotifyBroadcaster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	broadcaster := pgnotify.NewBroadcaster(db, dsn, "")
+
+	received := make(chan grole.Invalidation, 1)
+	done := make(chan error)
+	go func() {
+		done <- broadcaster.Subscribe(ctx, func(invalidation grole.Invalidation) {
+			select {
+			case received <- invalidation:
+			default:
+			}
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		err := broadcaster.Publish(ctx, grole.Invalidation{Entity: grole.EntityUser, UserID: 42, Origin: "a"})
+		require.NoError(t, err)
+		select {
+		case invalidation := <-received:
+			require.Equal(t, grole.EntityUser, invalidation.Entity)
+			require.Equal(t, uint(42), invalidation.UserID)
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestPgnotifyMutation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	broadcaster := pgnotify.NewBroadcaster(db, dsn, "grole_mutations")
+
+	received := make(chan grole.Invalidation, 10)
+	done := make(chan error)
+	go func() {
+		done <- broadcaster.Subscribe(ctx, func(invalidation grole.Invalidation) {
+			received <- invalidation
+		})
+	}()
+	// wait for the listening connection, the first notification may be missed.
+	require.Eventually(t, func() bool {
+		require.NoError(t, broadcaster.Publish(ctx, grole.Invalidation{Entity: grole.EntityUser, UserID: 1}))
+		select {
+		case <-received:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	for len(received) > 0 {
+		<-received
+	}
+
+	enforcer := grole.NewEnforcer(grole.Options{DB: db, CacheTTL: time.Minute, Broadcaster: broadcaster})
+	role, err := enforcer.FindOrCreateRole(models.Role{Name: "pgnotify-mutation"})
+	require.NoError(t, err)
+	defer enforcer.DeleteRole(role.ID)
+
+	select {
+	case invalidation := <-received:
+		require.Equal(t, grole.EntityRole, invalidation.Entity)
+		require.EqualValues(t, role.ID, invalidation.Key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no invalidation for the created role")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
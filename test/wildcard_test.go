@@ -0,0 +1,42 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/mousav1/grole"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPermission(t *testing.T) {
+	cases := []struct {
+		granted   string
+		requested string
+		separator string
+		match     bool
+	}{
+		{"articles.edit", "articles.edit", ".", true},
+		{"articles.*", "articles.edit", ".", true},
+		{"articles.*", "articles.edit.own", ".", true},
+		{"articles.*", "articles", ".", false},
+		{"*", "users.delete", ".", true},
+		{"*.edit", "articles.edit", ".", true},
+		{"*.edit", "articles.delete", ".", false},
+		{"articles.edit", "articles.delete", ".", false},
+		{"articles:*", "articles:edit", ":", true},
+		{"articles.*", "articles:edit", ":", false},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.match, grole.MatchPermission(c.granted, c.requested, c.separator), "%s %s", c.granted, c.requested)
+	}
+}
+
+func TestValidatePermissionName(t *testing.T) {
+	require.NoError(t, grole.ValidatePermissionName("manage-articles", "."))
+	require.NoError(t, grole.ValidatePermissionName("articles.*", "."))
+	require.NoError(t, grole.ValidatePermissionName("*", "."))
+
+	for _, name := range []string{"", "articles.", ".articles", "articles..edit", "art*cles.edit", "articles.ed*"} {
+		require.ErrorIs(t, grole.ValidatePermissionName(name, "."), grole.ErrInvalidPermissionName, name)
+	}
+}
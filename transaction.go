@@ -0,0 +1,212 @@
+package grole
+
+import (
+	"github.com/mousav1/grole/models"
+	"gorm.io/gorm"
+)
+
+// Tx is an enforcer bound to a database transaction, every grole operation
+// called on it is committed or rolled back together. Use DB to run your own
+// queries in the same transaction.
+type Tx struct {
+	*Enforcer
+}
+
+// Run the given function in a transaction, the transaction is rolled back
+// when it returns an error or panics and committed otherwise.
+// @param func(tx *Tx) error
+// @return error
+func (e *Enforcer) Transaction(fn func(tx *Tx) error) error {
+	return e.transaction(func(tx *Enforcer) error {
+		return fn(&Tx{Enforcer: tx})
+	})
+}
+
+// txState is shared by an outermost transaction and the ones nested in it.
+type txState struct {
+	changes []change
+}
+
+// transaction run fn with a copy of the enforcer bound to a transaction. The
+// changes recorded in the outermost transaction are passed to the before commit
+// hooks, written to the audit log and the outbox and published by a
+// TxBroadcaster before it commits, and applied once it commits.
+func (e *Enforcer) transaction(fn func(tx *Enforcer) error) error {
+	state := e.txState
+	if state == nil {
+		state = &txState{}
+	}
+	// the changes of a nested transaction are dropped with its savepoint when
+	// it fails, the outer transaction may still commit.
+	recorded := len(state.changes)
+	err := e.db.Transaction(func(db *gorm.DB) error {
+		tx := *e
+		tx.db = db
+		tx.txState = state
+		if err := fn(&tx); err != nil {
+			return err
+		}
+		if e.txState != nil {
+			return nil
+		}
+		if err := e.beforeCommit(&tx); err != nil {
+			return err
+		}
+		if err := writeAudit(db, state.changes); err != nil {
+			return err
+		}
+		if err := e.writeOutbox(db, state.changes); err != nil {
+			return err
+		}
+		return e.publishTx(db, state.changes)
+	})
+	if err != nil {
+		state.changes = state.changes[:recorded]
+		return err
+	}
+	if e.txState != nil {
+		return nil
+	}
+	e.committed(state.changes)
+	return nil
+}
+
+// changed record the changes made in the current transaction, by the actor
+// and in the scope of the enforcer.
+func (e *Enforcer) changed(changes ...change) {
+	if e.txState == nil {
+		return
+	}
+	for _, c := range changes {
+		c.scope = e.scope
+		c.actor = e.actor
+		*c.event.info() = EventInfo{Action: c.action, Actor: e.actor, Scope: e.scope}
+		e.txState.changes = append(e.txState.changes, c)
+	}
+}
+
+// insertMissing insert the record unless one matching the query exists, and
+// tell whether it was inserted.
+func insertMissing(db *gorm.DB, record interface{}, query string, args ...interface{}) (bool, error) {
+	var count int64
+	res := db.Model(record).Where(query, args...).Count(&count)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if count > 0 {
+		return false, nil
+	}
+	res = db.Create(record)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return true, nil
+}
+
+// findRolesByName find all the given roles with a single query without
+// duplicates, the first missing name is reported as ErrRoleNotFound.
+func (e *Enforcer) findRolesByName(names []string) ([]models.Role, error) {
+	roles := []models.Role{}
+	if len(names) == 0 {
+		return roles, nil
+	}
+	var found []models.Role
+	res := e.db.Where("name IN ?", names).Where("scope IN ?", e.scopes()).Find(&found)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	byName := make(map[string]models.Role, len(found))
+	for _, role := range found {
+		// a role of the enforcer scope wins over a global role with the same name.
+		if current, ok := byName[role.Name]; !ok || current.Scope == "" {
+			byName[role.Name] = role
+		}
+	}
+	seen := make(map[uint]bool, len(found))
+	for _, name := range names {
+		role, ok := byName[name]
+		if !ok {
+			return nil, newError(EntityRole, name, ErrRoleNotFound)
+		}
+		if !seen[role.ID] {
+			seen[role.ID] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// findPermissionsByName find all the given permissions with a single query without
+// duplicates, the first missing name is reported as ErrPermissionNotFound.
+func (e *Enforcer) findPermissionsByName(names []string) ([]models.Permission, error) {
+	permissions := []models.Permission{}
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	var found []models.Permission
+	res := e.db.Where("name IN ?", names).Find(&found)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	byName := make(map[string]models.Permission, len(found))
+	for _, permission := range found {
+		byName[permission.Name] = permission
+	}
+	seen := make(map[uint]bool, len(found))
+	for _, name := range names {
+		permission, ok := byName[name]
+		if !ok {
+			return nil, newError(EntityPermission, name, ErrPermissionNotFound)
+		}
+		if !seen[permission.ID] {
+			seen[permission.ID] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions, nil
+}
+
+// rolePermissionGranted tell whether the permission is granted to the role.
+func (e *Enforcer) rolePermissionGranted(roleId uint, permissionId uint) (bool, error) {
+	var count int64
+	res := e.db.Table("permission_role").Where("role_id = ?", roleId).Where("permission_id = ?", permissionId).Count(&count)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return count > 0, nil
+}
+
+// scopeRoles return the roles actively assigned to the user in the enforcer
+// scope only.
+func (e *Enforcer) scopeRoles(userID uint) ([]models.Role, error) {
+	var roles []models.Role
+	userRoles := e.db.Model(&models.UserRoles{}).Select("role_id").Where("user_id = ?", userID).Where("scope = ?", e.scope)
+	res := e.db.Where("id IN (?)", active(userRoles, "user_roles")).Order("id").Find(&roles)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return roles, nil
+}
+
+// difference return the records of a missing from b, compared by id.
+func difference[T any](a []T, b []T, id func(T) uint) []T {
+	ids := make(map[uint]bool, len(b))
+	for _, record := range b {
+		ids[id(record)] = true
+	}
+	var missing []T
+	for _, record := range a {
+		if !ids[id(record)] {
+			missing = append(missing, record)
+		}
+	}
+	return missing
+}
+
+func roleID(role models.Role) uint {
+	return role.ID
+}
+
+func permissionID(permission models.Permission) uint {
+	return permission.ID
+}
@@ -0,0 +1,116 @@
+package grole
+
+import (
+	"github.com/mousav1/grole/models"
+)
+
+// Give the given permissions directly to the user, without a role.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) GivePermissionToUser(userID uint, permissions ...string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		permissionModels, err := tx.findPermissionsByName(permissions)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissionModels {
+			created, err := insertMissing(tx.db, &models.UserPermissions{
+				UserID:       userID,
+				PermissionID: permission.ID,
+			}, "user_id = ? AND permission_id = ?", userID, permission.ID)
+			if err != nil {
+				return wrapError(EntityUser, userID, err)
+			}
+			if created {
+				tx.changed(userPermissionChange(ActionPermissionGranted, userID, permission))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Revoke the given direct permissions from the user.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) RevokePermissionFromUser(userID uint, permissions ...string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		permissionModels, err := tx.findPermissionsByName(permissions)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissionModels {
+			res := tx.db.Where("user_id = ?", userID).Where("permission_id = ?", permission.ID).Delete(&models.UserPermissions{})
+			if res.Error != nil {
+				return wrapError(EntityUser, userID, res.Error)
+			} else if res.RowsAffected < 1 {
+				return newError(EntityPermission, permission.Name, ErrPermissionNotAssigned)
+			}
+			tx.changed(userPermissionChange(ActionPermissionRevoked, userID, permission))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove all current direct permissions of the user and set the given ones.
+// @param uint, string
+// @return bool, error
+func (e *Enforcer) SyncUserPermissions(userID uint, permissions ...string) (bool, error) {
+	err := e.transaction(func(tx *Enforcer) error {
+		permissionModels, err := tx.findPermissionsByName(permissions)
+		if err != nil {
+			return err
+		}
+		current, err := tx.GetDirectPermissions(userID)
+		if err != nil {
+			return err
+		}
+
+		res := tx.db.Where("user_id = ?", userID).Delete(&models.UserPermissions{})
+		if res.Error != nil {
+			return wrapError(EntityUser, userID, res.Error)
+		}
+
+		for _, permission := range permissionModels {
+			res := tx.db.Create(&models.UserPermissions{
+				UserID:       userID,
+				PermissionID: permission.ID,
+			})
+			if res.Error != nil {
+				return wrapError(EntityUser, userID, res.Error)
+			}
+		}
+		for _, permission := range difference(current, permissionModels, permissionID) {
+			tx.changed(userPermissionChange(ActionPermissionRevoked, userID, permission))
+		}
+		for _, permission := range difference(permissionModels, current, permissionID) {
+			tx.changed(userPermissionChange(ActionPermissionGranted, userID, permission))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Return the permissions given directly to the user, without the ones of its roles.
+// @param uint
+// @return []models.Permission, error
+func (e *Enforcer) GetDirectPermissions(userID uint) ([]models.Permission, error) {
+	var permissions []models.Permission
+	res := e.db.Joins("JOIN user_permissions ON user_permissions.permission_id = permissions.id").
+		Where("user_permissions.user_id = ?", userID).
+		Find(&permissions)
+	if res.Error != nil {
+		return nil, wrapError(EntityUser, userID, res.Error)
+	}
+	return permissions, nil
+}
@@ -0,0 +1,88 @@
+package grole
+
+import (
+	"strings"
+
+	"github.com/mousav1/grole/models"
+)
+
+// DefaultPermissionSeparator separates the parts of a permission name, like
+// in "articles.edit", when Options.PermissionSeparator is empty.
+const DefaultPermissionSeparator = "."
+
+// Wildcard matches any part of a permission name, "articles.*" is granted
+// every articles permission and "*" every permission.
+const Wildcard = "*"
+
+// Determine if the granted permission, which may contain wildcards, satisfies
+// the requested one. A wildcard matches a single part of the name, except as
+// the last part where it also matches every deeper part.
+// @param string, string, string
+// @return bool
+func MatchPermission(granted string, requested string, separator string) bool {
+	if granted == requested {
+		return true
+	}
+	if !strings.Contains(granted, Wildcard) {
+		return false
+	}
+
+	grantedParts := strings.Split(granted, separator)
+	requestedParts := strings.Split(requested, separator)
+	for index, part := range grantedParts {
+		if index >= len(requestedParts) {
+			return false
+		}
+		if part == Wildcard {
+			if index == len(grantedParts)-1 {
+				return true
+			}
+			continue
+		}
+		if part != requestedParts[index] {
+			return false
+		}
+	}
+	return len(grantedParts) == len(requestedParts)
+}
+
+// Check that the permission name is well formed: it isn't empty, it has no
+// empty part and a wildcard is only used as a whole part.
+// @param string, string
+// @return error
+func ValidatePermissionName(name string, separator string) error {
+	if name == "" {
+		return newError(EntityPermission, name, ErrInvalidPermissionName)
+	}
+	for _, part := range strings.Split(name, separator) {
+		if part == "" || (part != Wildcard && strings.Contains(part, Wildcard)) {
+			return newError(EntityPermission, name, ErrInvalidPermissionName)
+		}
+	}
+	return nil
+}
+
+// Return the separator of the parts of permission names, "." unless
+// Options.PermissionSeparator is set.
+// @return string
+func (e *Enforcer) PermissionSeparator() string {
+	return e.separator()
+}
+
+// separator return the permission separator of the enforcer.
+func (e *Enforcer) separator() string {
+	if e.opts.PermissionSeparator == "" {
+		return DefaultPermissionSeparator
+	}
+	return e.opts.PermissionSeparator
+}
+
+// permissionGranted determine if one of the permissions satisfies the requested name.
+func (e *Enforcer) permissionGranted(permissions []models.Permission, name string) bool {
+	for _, permission := range permissions {
+		if MatchPermission(permission.Name, name, e.separator()) {
+			return true
+		}
+	}
+	return false
+}